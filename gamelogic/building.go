@@ -0,0 +1,154 @@
+// Package gamelogic provides functions for game logic
+package gamelogic
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/brct-james/guild-golems/log"
+	"github.com/brct-james/guild-golems/schema"
+)
+
+// Get how much a user can store at a locale, including bonuses from completed buildings
+func GetLocaleStorageCapacity(userData schema.User, locationSymbol string) float64 {
+	capacity := Locale_Storage_Capacity
+	for _, building := range userData.Buildings[locationSymbol] {
+		if building.IsComplete {
+			capacity += building.StorageBonus
+		}
+	}
+	return capacity
+}
+
+// Get how much storage a user has left at a locale
+func GetLocaleFreeStorage(userData *schema.User, locationSymbol string) float64 {
+	inventory := schema.GetLocationInventory(userData, locationSymbol)
+	return GetLocaleStorageCapacity(*userData, locationSymbol) - schema.CalculateUsedCapacity(inventory.Contents)
+}
+
+// Add quantity of resource to the user's inventory at locationSymbol if there is storage for it
+func DepositToLocale(userData *schema.User, locationSymbol string, resource schema.Resource, quantity int) error {
+	requiredCapacity := float64(quantity) * resource.CapacityPerUnit
+	freeStorage := GetLocaleFreeStorage(userData, locationSymbol)
+	if requiredCapacity > freeStorage {
+		return fmt.Errorf("%w: storage at %s has %v free but requires %v", schema.ErrInsufficientCapacity, locationSymbol, freeStorage, requiredCapacity)
+	}
+	return schema.AddToLocationInventory(userData, locationSymbol, resource, quantity)
+}
+
+// Set the golem to building blueprint at its location, starting a construction site at startTime if there is not one already
+func StartBuilding(userData *schema.User, golemIndex int, blueprint schema.Blueprint, startTime time.Time) error {
+	golem := &userData.Golems[golemIndex]
+	if schema.HasCompletedBuilding(*userData, golem.LocationSymbol, blueprint.Symbol) {
+		return fmt.Errorf("%w: %s already built at %s", schema.ErrRequirementsNotMet, blueprint.Symbol, golem.LocationSymbol)
+	}
+	if userData.Buildings == nil {
+		userData.Buildings = make(map[string]map[string]schema.Building)
+	}
+	if userData.Buildings[golem.LocationSymbol] == nil {
+		userData.Buildings[golem.LocationSymbol] = make(map[string]schema.Building)
+	}
+	if _, ok := userData.Buildings[golem.LocationSymbol][blueprint.Symbol]; !ok {
+		userData.Buildings[golem.LocationSymbol][blueprint.Symbol] = schema.NewBuilding(blueprint, golem.LocationSymbol, startTime.Unix())
+	}
+	golem.Status = "building"
+	golem.BuildInfo.BlueprintSymbol = blueprint.Symbol
+	return nil
+}
+
+// Get the engineers currently building blueprintSymbol at locationSymbol
+func getEngineersAtSite(userData schema.User, locationSymbol string, blueprintSymbol string) ([]int) {
+	engineerIndexes := make([]int, 0)
+	for i, golem := range userData.Golems {
+		if strings.EqualFold(golem.Status, "building") && strings.EqualFold(golem.LocationSymbol, locationSymbol) && strings.EqualFold(golem.BuildInfo.BlueprintSymbol, blueprintSymbol) {
+			engineerIndexes = append(engineerIndexes, i)
+		}
+	}
+	return engineerIndexes
+}
+
+// Update construction progress up to now based on the engineers present and the materials in the locale inventory, return the updated userData
+// Materials are drawn in proportion to progress, so construction stalls rather than fails when they run out
+func CalculateBuildingProgress(userData schema.User, blueprints map[string]schema.Blueprint, now int64) (schema.User) {
+	for locationSymbol, sites := range userData.Buildings {
+		for blueprintSymbol, building := range sites {
+			if building.IsComplete {
+				continue
+			}
+			secondsSinceTick := float64(now - building.LastProgressTick)
+			if secondsSinceTick <= 0 {
+				continue
+			}
+			// Idle sites move their tick forward too so they do not gain retroactive progress
+			building.LastProgressTick = now
+			engineerIndexes := getEngineersAtSite(userData, locationSymbol, blueprintSymbol)
+			blueprint, ok := blueprints[blueprintSymbol]
+			if len(engineerIndexes) < 1 || !ok {
+				userData.Buildings[locationSymbol][blueprintSymbol] = building
+				continue
+			}
+			// Work done is limited both by engineer time and by the materials available to draw on
+			targetFraction := 1.0
+			if building.BuildWork > 0 {
+				targetFraction = math.Min(1, (building.Progress + secondsSinceTick*float64(len(engineerIndexes))*Build_Rate_Engineer) / building.BuildWork)
+			}
+			targetFraction = math.Min(targetFraction, getMaterialLimitedFraction(&userData, building, blueprint))
+			inventory := schema.GetLocationInventory(&userData, locationSymbol)
+			for _, material := range blueprint.Materials {
+				needed := int(math.Ceil(targetFraction*float64(material.Quantity)-1e-9)) - building.MaterialsConsumed[material.ResourceSymbol]
+				if needed < 1 {
+					continue
+				}
+				_, removeErr := schema.RemoveFromInventory(inventory.Contents, material.ResourceSymbol, needed)
+				if removeErr != nil {
+					log.Error.Printf("Could not draw %d %s for %s at %s: %v", needed, material.ResourceSymbol, blueprintSymbol, locationSymbol, removeErr)
+					continue
+				}
+				building.MaterialsConsumed[material.ResourceSymbol] += needed
+			}
+			building.Progress = math.Max(building.Progress, targetFraction*building.BuildWork)
+			if targetFraction >= 1 {
+				log.Debug.Printf("%s complete at %s, setting engineers to idle", blueprintSymbol, locationSymbol)
+				building.Progress = building.BuildWork
+				building.IsComplete = true
+				for _, i := range engineerIndexes {
+					userData.Golems[i].Status = "idle"
+					userData.Golems[i].IdleSince = now
+					GrantGolemExperience(&userData.Golems[i], Experience_Per_Build, now)
+				}
+			}
+			userData.Buildings[locationSymbol][blueprintSymbol] = building
+		}
+	}
+	return userData
+}
+
+// Get the fraction of building that the materials already consumed plus those in the locale inventory are enough for
+func getMaterialLimitedFraction(userData *schema.User, building schema.Building, blueprint schema.Blueprint) float64 {
+	fraction := 1.0
+	inventory := schema.GetLocationInventory(userData, building.LocationSymbol)
+	for _, material := range blueprint.Materials {
+		if material.Quantity <= 0 {
+			continue
+		}
+		available := float64(building.MaterialsConsumed[material.ResourceSymbol] + inventory.Contents[material.ResourceSymbol].Quantity)
+		fraction = math.Min(fraction, available/float64(material.Quantity))
+	}
+	return fraction
+}
+
+// Estimate when building will complete with the engineers currently at the site
+// Returns false if nobody is building it or it would stall for lack of materials first
+func EstimateBuildingCompletion(userData *schema.User, building schema.Building, blueprint schema.Blueprint) (int64, bool) {
+	if building.IsComplete || getMaterialLimitedFraction(userData, building, blueprint) < 1 {
+		return 0, false
+	}
+	numEngineers := len(getEngineersAtSite(*userData, building.LocationSymbol, blueprint.Symbol))
+	if numEngineers < 1 {
+		return 0, false
+	}
+	remainingWork := math.Max(0, building.BuildWork - building.Progress)
+	return building.LastProgressTick + int64(math.Ceil(remainingWork/(float64(numEngineers)*Build_Rate_Engineer))), true
+}
@@ -0,0 +1,78 @@
+// Package gamelogic provides functions for game logic
+package gamelogic
+
+import (
+	"fmt"
+
+	"github.com/brct-james/guild-golems/schema"
+)
+
+// Move resources from the locale inventory at the golem's location into its cargo
+// Validates every requested resource before moving anything, so a failed load leaves userData untouched
+func LoadGolemCargo(userData *schema.User, golemIndex int, resources map[string]int) error {
+	golem := &userData.Golems[golemIndex]
+	inventory := schema.GetLocationInventory(userData, golem.LocationSymbol)
+	requiredCapacity := 0.0
+	for symbol, quantity := range resources {
+		if quantity <= 0 {
+			return fmt.Errorf("cannot load non-positive quantity %d of %s", quantity, symbol)
+		}
+		stack, ok := inventory.Contents[symbol]
+		if !ok || stack.Quantity < quantity {
+			return fmt.Errorf("%w: have %d %s at %s but requires %d", schema.ErrInsufficientQuantity, stack.Quantity, symbol, golem.LocationSymbol, quantity)
+		}
+		requiredCapacity += float64(quantity) * stack.CapacityPerUnit
+	}
+	freeCapacity := schema.GetGolemFreeCapacity(*golem)
+	if requiredCapacity > freeCapacity {
+		return fmt.Errorf("%w: golem %s has %v free but requires %v", schema.ErrInsufficientCapacity, golem.Symbol, freeCapacity, requiredCapacity)
+	}
+	for symbol, quantity := range resources {
+		transferErr := schema.TransferInventoryResource(inventory.Contents, golem.Cargo, symbol, quantity)
+		if transferErr != nil {
+			return transferErr
+		}
+	}
+	return nil
+}
+
+// Move resources from the golem's cargo into the locale inventory at its location
+// Validates every requested resource before moving anything, so a failed unload leaves userData untouched
+func UnloadGolemCargo(userData *schema.User, golemIndex int, resources map[string]int) error {
+	golem := &userData.Golems[golemIndex]
+	requiredStorage := 0.0
+	for symbol, quantity := range resources {
+		if quantity <= 0 {
+			return fmt.Errorf("cannot unload non-positive quantity %d of %s", quantity, symbol)
+		}
+		stack, ok := golem.Cargo[symbol]
+		if !ok || stack.Quantity < quantity {
+			return fmt.Errorf("%w: golem %s has %d %s but requires %d", schema.ErrInsufficientQuantity, golem.Symbol, stack.Quantity, symbol, quantity)
+		}
+		requiredStorage += float64(quantity) * stack.CapacityPerUnit
+	}
+	freeStorage := GetLocaleFreeStorage(userData, golem.LocationSymbol)
+	if requiredStorage > freeStorage {
+		return fmt.Errorf("%w: storage at %s has %v free but requires %v", schema.ErrInsufficientCapacity, golem.LocationSymbol, freeStorage, requiredStorage)
+	}
+	inventory := schema.GetLocationInventory(userData, golem.LocationSymbol)
+	for symbol, quantity := range resources {
+		transferErr := schema.TransferInventoryResource(golem.Cargo, inventory.Contents, symbol, quantity)
+		if transferErr != nil {
+			return transferErr
+		}
+	}
+	return nil
+}
+
+// Move the golem's entire cargo into the locale inventory at its location
+func UnloadAllGolemCargo(userData *schema.User, golemIndex int) error {
+	resources := make(map[string]int)
+	for symbol, stack := range userData.Golems[golemIndex].Cargo {
+		resources[symbol] = stack.Quantity
+	}
+	if len(resources) < 1 {
+		return nil
+	}
+	return UnloadGolemCargo(userData, golemIndex, resources)
+}
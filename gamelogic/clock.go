@@ -0,0 +1,35 @@
+// Package gamelogic provides functions for game logic
+package gamelogic
+
+import (
+	"time"
+)
+
+// Source of the current time for the game simulation
+type Clock interface {
+	Now() time.Time
+}
+
+// Clock reading the system time
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Clock that only moves when told to, used to fast-forward the simulation
+type ManualClock struct {
+	Time time.Time
+}
+
+func (c *ManualClock) Now() time.Time {
+	return c.Time
+}
+
+// Move the clock forward by d
+func (c *ManualClock) Advance(d time.Duration) {
+	c.Time = c.Time.Add(d)
+}
+
+// Clock used by the game, replace with a ManualClock to control time
+var GameClock Clock = SystemClock{}
@@ -0,0 +1,39 @@
+// Package gamelogic provides functions for game logic
+package gamelogic
+
+import (
+	"fmt"
+
+	"github.com/brct-james/guild-golems/schema"
+)
+
+// Pay mana, coins and resources from the inventory at localeSymbol for what, e.g. a research symbol
+// Nothing is paid unless every cost can be
+func payCosts(userData *schema.User, what string, manaCost float64, coinCost uint64, resourceCosts []schema.RecipeComponent, localeSymbol string) error {
+	if userData.Mana < manaCost {
+		return fmt.Errorf("%w: have %v but %s costs %v", schema.ErrInsufficientMana, userData.Mana, what, manaCost)
+	}
+	if userData.Coins < coinCost {
+		return fmt.Errorf("%w: have %d but %s costs %d", schema.ErrInsufficientCoins, userData.Coins, what, coinCost)
+	}
+	// Totalled first so a resource listed twice is checked against the combined quantity
+	required := make(map[string]int)
+	for _, cost := range resourceCosts {
+		required[cost.ResourceSymbol] += cost.Quantity
+	}
+	inventory := schema.GetLocationInventory(userData, localeSymbol)
+	for resourceSymbol, quantity := range required {
+		if inventory.Contents[resourceSymbol].Quantity < quantity {
+			return fmt.Errorf("%w: %s costs %d %s at %s", schema.ErrInsufficientQuantity, what, quantity, resourceSymbol, localeSymbol)
+		}
+	}
+	for resourceSymbol, quantity := range required {
+		_, removeErr := schema.RemoveFromLocationInventory(userData, localeSymbol, resourceSymbol, quantity)
+		if removeErr != nil {
+			return removeErr
+		}
+	}
+	userData.Mana -= manaCost
+	userData.Coins -= coinCost
+	return nil
+}
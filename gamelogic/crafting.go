@@ -0,0 +1,75 @@
+// Package gamelogic provides functions for game logic
+package gamelogic
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brct-james/guild-golems/log"
+	"github.com/brct-james/guild-golems/schema"
+	"github.com/brct-james/guild-golems/timecalc"
+)
+
+// Check that the golem is somewhere the recipe can be crafted
+func CheckRecipeRequirements(userData schema.User, golem schema.Golem, recipe schema.Recipe) error {
+	if recipe.RequiredLocaleSymbol != "" && !strings.EqualFold(recipe.RequiredLocaleSymbol, golem.LocationSymbol) {
+		return fmt.Errorf("%w: %s can only be crafted at %s", schema.ErrRequirementsNotMet, recipe.Symbol, recipe.RequiredLocaleSymbol)
+	}
+	if recipe.RequiredBuildingSymbol != "" && !schema.HasCompletedBuilding(userData, golem.LocationSymbol, recipe.RequiredBuildingSymbol) {
+		return fmt.Errorf("%w: %s requires a %s at %s", schema.ErrRequirementsNotMet, recipe.Symbol, recipe.RequiredBuildingSymbol, golem.LocationSymbol)
+	}
+	return nil
+}
+
+// Consume the recipe inputs from the locale inventory at the golem's location and set the golem to crafting from startTime
+// Validates every input before consuming anything, so a failed start leaves userData untouched
+func StartCraft(userData *schema.User, golemIndex int, recipe schema.Recipe, startTime time.Time) error {
+	golem := &userData.Golems[golemIndex]
+	requirementsErr := CheckRecipeRequirements(*userData, *golem, recipe)
+	if requirementsErr != nil {
+		return requirementsErr
+	}
+	inventory := schema.GetLocationInventory(userData, golem.LocationSymbol)
+	required := make(map[string]int)
+	for _, input := range recipe.Inputs {
+		required[input.ResourceSymbol] += input.Quantity
+	}
+	for symbol, quantity := range required {
+		stack, ok := inventory.Contents[symbol]
+		if !ok || stack.Quantity < quantity {
+			return fmt.Errorf("%w: have %d %s at %s but %s requires %d", schema.ErrInsufficientQuantity, stack.Quantity, symbol, golem.LocationSymbol, recipe.Symbol, quantity)
+		}
+	}
+	for symbol, quantity := range required {
+		_, removeErr := schema.RemoveFromInventory(inventory.Contents, symbol, quantity)
+		if removeErr != nil {
+			return removeErr
+		}
+	}
+	golem.Status = "crafting"
+	golem.CraftInfo.RecipeSymbol = recipe.Symbol
+	golem.CraftInfo.CompletionTime = timecalc.AddSecondsToTimestamp(startTime, recipe.CraftTime).Unix()
+	return nil
+}
+
+// Finish the golem's craft, depositing recipe outputs into the locale inventory and setting it idle from its completion time
+func CompleteCraft(userData *schema.User, golemIndex int, recipe schema.Recipe, resources map[string]schema.Resource) {
+	golem := &userData.Golems[golemIndex]
+	for _, output := range recipe.Outputs {
+		resource, ok := resources[output.ResourceSymbol]
+		if !ok {
+			log.Error.Printf("Recipe %s produces unknown resource %s", recipe.Symbol, output.ResourceSymbol)
+			continue
+		}
+		// Outputs ignore the storage limit as their inputs were already taken from the same storage
+		addErr := schema.AddToLocationInventory(userData, golem.LocationSymbol, resource, output.Quantity)
+		if addErr != nil {
+			log.Error.Printf("Could not add crafting output to inventory for golem %s: %v", golem.Symbol, addErr)
+		}
+	}
+	log.Debug.Printf("Golem %s finished %s, setting to idle", golem.Symbol, recipe.Symbol)
+	GrantGolemExperience(golem, Experience_Per_Craft, golem.CraftInfo.CompletionTime)
+	golem.Status = "idle"
+	golem.IdleSince = golem.CraftInfo.CompletionTime
+}
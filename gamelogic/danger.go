@@ -0,0 +1,64 @@
+// Package gamelogic provides functions for game logic
+package gamelogic
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/brct-james/guild-golems/schema"
+)
+
+// Possible incidents when a golem arrives from a dangerous route
+var dangerOutcomes = []string{"cargo_lost", "delayed", "damaged"}
+
+// Roll whether anything happened on the golem's trip, based on TravelInfo.RouteDanger
+// Returns the rolled outcome, or "" if the trip was uneventful
+func RollDangerOutcome(golem schema.Golem, roller *Roller) string {
+	chance := float64(golem.TravelInfo.RouteDanger) * Danger_Chance_Per_Level
+	if chance <= 0 || roller.Float64() >= chance {
+		return ""
+	}
+	outcome := dangerOutcomes[roller.Intn(len(dangerOutcomes))]
+	if strings.EqualFold(outcome, "cargo_lost") && len(golem.Cargo) < 1 {
+		// Nothing to lose, the golem takes the hit instead
+		outcome = "damaged"
+	}
+	return outcome
+}
+
+// Apply outcome to the golem, logging it to the golem's event log at timestamp
+// Route danger is cleared so the outcome is only applied once, even if the golem is delayed
+func ApplyDangerOutcome(golem *schema.Golem, outcome string, timestamp int64) {
+	dangerLevel := golem.TravelInfo.RouteDanger
+	golem.TravelInfo.RouteDanger = 0
+	switch outcome {
+	case "cargo_lost":
+		lossFraction := math.Min(1, float64(dangerLevel)*Danger_Cargo_Loss_Per_Level)
+		// Sort symbols so the event description is stable
+		symbols := make([]string, 0, len(golem.Cargo))
+		for symbol := range golem.Cargo {
+			symbols = append(symbols, symbol)
+		}
+		sort.Strings(symbols)
+		lost := make([]string, 0, len(symbols))
+		for _, symbol := range symbols {
+			quantity := int(math.Ceil(float64(golem.Cargo[symbol].Quantity) * lossFraction))
+			removed, removeErr := schema.RemoveFromInventory(golem.Cargo, symbol, quantity)
+			if removeErr != nil {
+				continue
+			}
+			lost = append(lost, fmt.Sprintf("%d %s", removed.Quantity, symbol))
+		}
+		schema.AddGolemEvent(golem, timestamp, outcome, fmt.Sprintf("Lost %s on the road to %s", strings.Join(lost, ", "), golem.TravelInfo.DestinationSymbol))
+	case "delayed":
+		delay := dangerLevel * Danger_Delay_Per_Level
+		golem.TravelInfo.ArrivalTime += int64(delay)
+		schema.AddGolemEvent(golem, timestamp, outcome, fmt.Sprintf("Delayed %d seconds on the road to %s", delay, golem.TravelInfo.DestinationSymbol))
+	case "damaged":
+		damage := dangerLevel * Danger_Damage_Per_Level
+		golem.Health = int(math.Max(0, float64(golem.Health - damage)))
+		schema.AddGolemEvent(golem, timestamp, outcome, fmt.Sprintf("Took %d damage on the road to %s", damage, golem.TravelInfo.DestinationSymbol))
+	}
+}
@@ -0,0 +1,103 @@
+// Package gamelogic provides functions for game logic
+package gamelogic
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/brct-james/guild-golems/schema"
+)
+
+// Get the golem's energy change per second in its current status, negative while working
+func getEnergyRate(golem schema.Golem) float64 {
+	if strings.EqualFold(golem.Status, "idle") {
+		return golem.EnergyRegen
+	}
+	return -Energy_Drain_Per_Second[strings.ToLower(golem.Status)]
+}
+
+// Check whether the golem stops working when its energy runs out
+func isInterruptibleByEnergy(golem schema.Golem) bool {
+	return Energy_Interruptible_Statuses[strings.ToLower(golem.Status)]
+}
+
+// Update every golem's energy from its last energy tick to now, return the updated userData
+// Golems in an interruptible status are set idle once they run out, so call this before any status change
+func CalculateGolemEnergy(userData schema.User, now int64) (schema.User) {
+	for i := range userData.Golems {
+		golem := &userData.Golems[i]
+		if golem.EnergyCap <= 0 {
+			// Golems saved before energy existed never tire
+			continue
+		}
+		if golem.LastEnergyTick <= 0 {
+			golem.LastEnergyTick = now
+		}
+		secondsSinceTick := float64(now - golem.LastEnergyTick)
+		if secondsSinceTick > 0 {
+			golem.Energy = math.Max(0, math.Min(golem.EnergyCap, golem.Energy + secondsSinceTick*getEnergyRate(*golem)))
+			golem.LastEnergyTick = now
+		}
+		if golem.Energy < 1e-9 {
+			// Float error from many small updates would otherwise leave a sliver that delays exhaustion by a second
+			golem.Energy = 0
+		}
+		if golem.Energy <= 0 && isInterruptibleByEnergy(*golem) {
+			schema.AddGolemEvent(golem, now, "exhausted", fmt.Sprintf("Ran out of energy while %s", golem.Status))
+			golem.Status = "idle"
+			golem.IdleSince = now
+		}
+	}
+	return userData
+}
+
+// Get when the golem will run out of energy in its current status, false if it never will
+func GetEnergyExhaustionTime(golem schema.Golem) (int64, bool) {
+	drain := -getEnergyRate(golem)
+	if golem.EnergyCap <= 0 || drain <= 0 || !isInterruptibleByEnergy(golem) {
+		return 0, false
+	}
+	return golem.LastEnergyTick + int64(math.Ceil(golem.Energy/drain)), true
+}
+
+// Get when the golem will have enough energy to start work, now or later if it has to regenerate first
+// Returns false if it never will
+func GetEnergyReadyTime(golem schema.Golem) (int64, bool) {
+	if golem.EnergyCap <= 0 || golem.Energy >= Energy_Min_To_Work {
+		return golem.LastEnergyTick, true
+	}
+	if golem.EnergyRegen <= 0 || golem.EnergyCap < Energy_Min_To_Work {
+		return 0, false
+	}
+	return golem.LastEnergyTick + int64(math.Ceil((Energy_Min_To_Work - golem.Energy)/golem.EnergyRegen)), true
+}
+
+// Check the golem has the energy to start a task that takes time
+func CheckGolemEnergy(golem schema.Golem) error {
+	if golem.EnergyCap > 0 && golem.Energy < Energy_Min_To_Work {
+		return fmt.Errorf("%w: %s has %v energy but needs %v to start work", schema.ErrGolemExhausted, golem.Symbol, math.Floor(golem.Energy), Energy_Min_To_Work)
+	}
+	return nil
+}
+
+// Get the mana cost of refilling the golem's energy with ritual, whose mana cost is per point restored
+func GetRechargeCost(golem schema.Golem, ritual schema.Ritual) float64 {
+	return math.Ceil(math.Max(0, golem.EnergyCap - golem.Energy) * ritual.ManaCost)
+}
+
+// Spend mana to refill the golem's energy, drawing first from the ritual circle where it stands
+// Returns the mana spent
+func RechargeGolem(userData *schema.User, golemIndex int, ritual schema.Ritual) (float64, error) {
+	golem := &userData.Golems[golemIndex]
+	if golem.EnergyCap - golem.Energy <= 0 {
+		return 0, fmt.Errorf("%w: %s already has full energy", schema.ErrRequirementsNotMet, golem.Symbol)
+	}
+	cost := GetRechargeCost(*golem, ritual)
+	spendErr := spendRitualMana(userData, golem.LocationSymbol, cost)
+	if spendErr != nil {
+		return 0, spendErr
+	}
+	golem.Energy = golem.EnergyCap
+	return cost, nil
+}
@@ -0,0 +1,88 @@
+// Package gamelogic provides functions for game logic
+package gamelogic
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/brct-james/guild-golems/schema"
+)
+
+// Get the capacity golems of archetype are summoned with
+func GetArchetypeBaseCapacity(archetype string) float64 {
+	switch archetype {
+	case "invoker":
+		return Capacity_Invoker
+	case "harvester":
+		return Capacity_Harvester
+	case "courier":
+		return Capacity_Courier
+	case "merchant":
+		return Capacity_Merchant
+	case "artisan":
+		return Capacity_Artisan
+	case "engineer":
+		return Capacity_Engineer
+	}
+	return 0
+}
+
+// Get the total experience needed to reach level
+func GetExperienceForLevel(level int) int {
+	return Experience_Per_Level * (level - 1) * level / 2
+}
+
+// Get the number of levels the golem has gained, golems saved before levels existed count as level 1
+func getLevelsGained(golem schema.Golem) float64 {
+	return math.Max(0, float64(golem.Level - 1))
+}
+
+// Get the mana regen per second the golem adds while invoking
+func GetInvokerManaRegen(golem schema.Golem) float64 {
+	return Mana_Regen_Invoker * (1 + getLevelsGained(golem)*Invoke_Bonus_Per_Level)
+}
+
+// Get the seconds between the golem's harvests at node, never less than half the node's harvest_time
+func GetGolemHarvestTime(golem schema.Golem, node schema.ResourceNode) int64 {
+	speedup := math.Min(0.5, getLevelsGained(golem)*Harvest_Speed_Bonus_Per_Level)
+	return int64(math.Max(1, math.Ceil(float64(node.HarvestTime)*(1-speedup))))
+}
+
+// Award the golem experience for a finished job at timestamp, levelling it up as thresholds are passed
+func GrantGolemExperience(golem *schema.Golem, experience int, timestamp int64) {
+	if golem.Level < 1 {
+		golem.Level = 1
+	}
+	golem.Experience += experience
+	for golem.Level < Golem_Max_Level && golem.Experience >= GetExperienceForLevel(golem.Level+1) {
+		golem.Level++
+		// Harvest speed and invoke output are derived from level, only capacity is stored
+		if !strings.EqualFold(golem.Archetype, "invoker") && !strings.EqualFold(golem.Archetype, "harvester") {
+			golem.Capacity += GetArchetypeBaseCapacity(golem.Archetype) * Capacity_Bonus_Per_Level
+		}
+		schema.AddGolemEvent(golem, timestamp, "level_up", fmt.Sprintf("Reached level %d", golem.Level))
+	}
+}
+
+// Get the mana cost of the upgrade ritual for the golem's next level
+func GetUpgradeCost(golem schema.Golem, ritual schema.Ritual) float64 {
+	return ritual.ManaCost * math.Max(1, float64(golem.Level))
+}
+
+// Spend mana to raise the golem one level at timestamp with ritual, drawing first from the ritual circle where it stands
+// Returns the mana spent
+func UpgradeGolem(userData *schema.User, golemIndex int, ritual schema.Ritual, timestamp int64) (float64, error) {
+	golem := &userData.Golems[golemIndex]
+	if golem.Level >= Golem_Max_Level {
+		return 0, fmt.Errorf("%w: %s is already level %d", schema.ErrRequirementsNotMet, golem.Symbol, golem.Level)
+	}
+	cost := GetUpgradeCost(*golem, ritual)
+	spendErr := spendRitualMana(userData, golem.LocationSymbol, cost)
+	if spendErr != nil {
+		return 0, spendErr
+	}
+	missing := GetExperienceForLevel(int(math.Max(1, float64(golem.Level)))+1) - golem.Experience
+	GrantGolemExperience(golem, int(math.Max(0, float64(missing))), timestamp)
+	return cost, nil
+}
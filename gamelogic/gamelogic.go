@@ -3,17 +3,15 @@ package gamelogic
 
 import (
 	"github.com/brct-james/guild-golems/log"
+	"github.com/brct-james/guild-golems/rdb"
 	"github.com/brct-james/guild-golems/schema"
 )
 
-// Calculates all updates to the user object based on game logic, saving to db & returns the updated user
-func CalculateUserUpdates(userData schema.User) (schema.User) {
+// Calculates all updates to the user object based on game logic up to the GameClock's now & returns the updated user, caller is responsible for saving to db
+func CalculateUserUpdates(userData schema.User, wdb rdb.Database) (schema.User) {
 	log.Debug.Println(log.Cyan("-- Begin CalculateUserUpdates --"))
-	userData = CalculateManaRegen(userData)
-	userData = CalculateTravelArrived(userData)
-
-	// Save changes to DB
-	
+	userData = BackfillGolemHealth(userData)
+	userData = SimulateUserUpdates(userData, wdb, GameClock)
 	log.Debug.Println(log.Cyan("-- End CalculateUserUpdates --"))
 	return userData
-}
\ No newline at end of file
+}
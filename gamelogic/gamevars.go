@@ -3,4 +3,68 @@ package gamelogic
 
 // Golem Capacity
 var Capacity_Invoker float64 = 0
-var Capacity_Harvester float64 = 10
\ No newline at end of file
+var Capacity_Harvester float64 = 10
+var Capacity_Courier float64 = 50
+var Capacity_Merchant float64 = 20
+var Capacity_Artisan float64 = 10
+var Capacity_Engineer float64 = 10
+
+// Mana regen per second from each invoking invoker at level 1
+var Mana_Regen_Invoker float64 = 0.5
+
+// Mana each locale's ritual circle holds, invoker output past this flows into the user's mana
+var Ritual_Circle_Mana_Cap float64 = 3600
+
+// Most times a ritual may be repeated in one request
+var Max_Ritual_Repetitions int = 100
+
+// Teleport, mana per hop for every unit of capacity the golem's cargo fills, on top of the ritual's mana cost per hop
+var Teleport_Mana_Per_Capacity float64 = 10
+
+// Experience awarded for each finished job
+var Experience_Per_Harvest int = 1
+var Experience_Per_Trip int = 5
+var Experience_Per_Craft int = 10
+var Experience_Per_Build int = 20
+// Experience needed to go from level L to L+1 is Experience_Per_Level * L
+var Experience_Per_Level int = 100
+var Golem_Max_Level int = 10
+// Bonus per level above 1: invokers regen more mana, harvesters harvest faster, every other archetype carries more
+var Invoke_Bonus_Per_Level float64 = 0.1
+var Harvest_Speed_Bonus_Per_Level float64 = 0.05
+var Capacity_Bonus_Per_Level float64 = 0.1
+
+// Energy drained per second by each status, statuses not listed do not drain energy
+// Invoking is left out so invokers can be left invoking rather than needing constant recharges
+var Energy_Drain_Per_Second = map[string]float64{
+	"harvesting": 0.01,
+	"building": 0.01,
+	"crafting": 0.01,
+	"traveling": 0.005,
+	"delivering": 0.005,
+}
+// Statuses a golem is pulled out of when its energy runs out, trips and crafts are always finished
+var Energy_Interruptible_Statuses = map[string]bool{"harvesting": true, "building": true}
+// Energy needed to start any task that takes time
+var Energy_Min_To_Work float64 = 25
+
+// Dismissal, fraction of the summon ritual's mana cost refunded when a golem is dismissed
+var Golem_Dismiss_Refund_Fraction float64 = 0.5
+
+// Locale Storage
+var Locale_Storage_Capacity float64 = 1000
+
+// Construction, work added per second by each engineer on site
+var Build_Rate_Engineer float64 = 1
+
+// Route Danger, the chance of an incident on arrival is Danger_Chance_Per_Level * route danger level
+var Danger_Chance_Per_Level float64 = 0.05
+// Fraction of each cargo stack lost per danger level, rounded up
+var Danger_Cargo_Loss_Per_Level float64 = 0.1
+// Seconds added to arrival per danger level
+var Danger_Delay_Per_Level int = 5
+// Health lost per danger level
+var Danger_Damage_Per_Level int = 5
+
+// Simulation, max events replayed per update, any left over are replayed on the next update
+var Max_Simulation_Events int = 100000
@@ -0,0 +1,63 @@
+// Package gamelogic provides functions for game logic
+package gamelogic
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/brct-james/guild-golems/schema"
+)
+
+// Get the next ID for a golem of archetype and advance the user's counter, IDs are never reused even after dismissal
+// Users from before counters existed are seeded from the highest ID they already have
+func NextGolemId(userData *schema.User, archetype string) int {
+	if userData.GolemIdCounters == nil {
+		userData.GolemIdCounters = make(map[string]int)
+	}
+	nextId, ok := userData.GolemIdCounters[archetype]
+	if !ok {
+		for _, golem := range schema.FilterGolemListByArchetype(userData.Golems, archetype) {
+			symbolParts := strings.Split(golem.Symbol, "-")
+			golemId, err := strconv.Atoi(symbolParts[len(symbolParts)-1])
+			if err == nil && golemId >= nextId {
+				nextId = golemId + 1
+			}
+		}
+	}
+	userData.GolemIdCounters[archetype] = nextId + 1
+	return nextId
+}
+
+// Remove the golem from the user, unloading its cargo at its location and refunding part of the mana paid to summon it
+// Golems in transit or crafting must finish first, so their cargo and craft inputs are never lost
+// The refund is capped at the room left under the mana cap, the response reports how much was due, refunded and forfeited
+func DismissGolem(userData *schema.User, golemIndex int) (schema.GolemDismissalResponse, error) {
+	golem := userData.Golems[golemIndex]
+	dismissal := schema.GolemDismissalResponse{Golem: golem, Mana: userData.Mana}
+	if strings.EqualFold(golem.LocationSymbol, schema.In_Transit_Location_Symbol) {
+		return dismissal, fmt.Errorf("%w: %s must arrive before it can be dismissed", schema.ErrGolemInTransit, golem.Symbol)
+	}
+	if strings.EqualFold(golem.Status, "crafting") {
+		return dismissal, fmt.Errorf("%w: %s must finish crafting %s before it can be dismissed", schema.ErrGolemBlocked, golem.Symbol, golem.CraftInfo.RecipeSymbol)
+	}
+	unloadErr := UnloadAllGolemCargo(userData, golemIndex)
+	if unloadErr != nil {
+		return dismissal, unloadErr
+	}
+	dismissal.Golem = userData.Golems[golemIndex]
+	userData.Golems = append(userData.Golems[:golemIndex], userData.Golems[golemIndex+1:]...)
+	summonCost := golem.SummonManaCost
+	if summonCost <= 0 {
+		// Golems summoned before the cost was recorded are refunded as if summoned by the cheapest ritual
+		summonRitual, _ := schema.GetSummonRitual(golem.Archetype)
+		summonCost = summonRitual.ManaCost
+	}
+	dismissal.ManaRefundDue = summonCost * Golem_Dismiss_Refund_Fraction
+	dismissal.ManaRefunded = math.Min(dismissal.ManaRefundDue, math.Max(0, userData.ManaCap-userData.Mana))
+	dismissal.ManaForfeited = dismissal.ManaRefundDue - dismissal.ManaRefunded
+	userData.Mana += dismissal.ManaRefunded
+	dismissal.Mana = userData.Mana
+	return dismissal, nil
+}
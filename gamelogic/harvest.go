@@ -0,0 +1,72 @@
+// Package gamelogic provides functions for game logic
+package gamelogic
+
+import (
+	"time"
+
+	"github.com/brct-james/guild-golems/log"
+	"github.com/brct-james/guild-golems/schema"
+)
+
+// Set the golem harvesting node from startTime, each harvest tick is replayed lazily by the simulation
+// autoDeposit unloads cargo into the locale inventory when full rather than stopping
+func StartHarvest(userData *schema.User, golemIndex int, node schema.ResourceNode, autoDeposit bool, startTime time.Time) {
+	golem := &userData.Golems[golemIndex]
+	golem.Status = "harvesting"
+	golem.HarvestInfo.NodeSymbol = node.Symbol
+	golem.HarvestInfo.LastHarvestTick = startTime.Unix()
+	golem.HarvestInfo.AutoDeposit = autoDeposit
+}
+
+// Roll the drop tables of node numHarvests times, returning the quantity gathered keyed by resource symbol
+func RollHarvestYield(node schema.ResourceNode, numHarvests int64, roller *Roller) (map[string]int) {
+	yield := make(map[string]int)
+	for _, dropTable := range node.DropTables {
+		for n := int64(0); n < numHarvests; n++ {
+			if roller.Float64() < dropTable.Rarity {
+				yield[dropTable.ResourceSymbol] += dropTable.HarvestAmount
+			}
+		}
+	}
+	return yield
+}
+
+// Put harvest yield into the golem's cargo, auto-depositing into the locale inventory if enabled
+// Returns true if the golem could not hold the entire yield
+func storeHarvestYield(userData *schema.User, golemIndex int, node schema.ResourceNode, yield map[string]int, resources map[string]schema.Resource) (bool) {
+	golem := &userData.Golems[golemIndex]
+	isFull := false
+	// Use drop table order rather than map order so the same yield always fills cargo the same way
+	for _, dropTable := range node.DropTables {
+		quantity, ok := yield[dropTable.ResourceSymbol]
+		if !ok || quantity < 1 {
+			continue
+		}
+		delete(yield, dropTable.ResourceSymbol)
+		resource, ok := resources[dropTable.ResourceSymbol]
+		if !ok {
+			log.Error.Printf("Resource node %s drops unknown resource %s", node.Symbol, dropTable.ResourceSymbol)
+			continue
+		}
+		fits := schema.UnitsThatFit(resource, schema.GetGolemFreeCapacity(*golem))
+		if fits < quantity && golem.HarvestInfo.AutoDeposit {
+			depositErr := UnloadAllGolemCargo(userData, golemIndex)
+			if depositErr != nil {
+				log.Error.Printf("Could not auto-deposit cargo for golem %s: %v", golem.Symbol, depositErr)
+			}
+			fits = schema.UnitsThatFit(resource, schema.GetGolemFreeCapacity(*golem))
+		}
+		if fits < quantity {
+			isFull = true
+			quantity = fits
+		}
+		if quantity < 1 {
+			continue
+		}
+		addErr := schema.AddToInventory(golem.Cargo, resource, quantity)
+		if addErr != nil {
+			log.Error.Printf("Could not add harvest yield to cargo for golem %s: %v", golem.Symbol, addErr)
+		}
+	}
+	return isFull
+}
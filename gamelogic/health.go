@@ -0,0 +1,50 @@
+// Package gamelogic provides functions for game logic
+package gamelogic
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/brct-james/guild-golems/schema"
+)
+
+// Give golems saved before health existed full health, they load with 0 health and would otherwise be broken
+// Such golems have no MaxHealth, golems damaged down to 0 keep theirs
+func BackfillGolemHealth(userData schema.User) (schema.User) {
+	for i := range userData.Golems {
+		golem := &userData.Golems[i]
+		if golem.MaxHealth <= 0 {
+			golem.MaxHealth = schema.Golem_Max_Health
+			if golem.Health <= 0 {
+				golem.Health = golem.MaxHealth
+			}
+		}
+	}
+	return userData
+}
+
+// Check the golem has health left to start a task that takes time
+func CheckGolemHealth(golem schema.Golem) error {
+	if golem.Health <= 0 {
+		return fmt.Errorf("%w: %s has no health left, repair it first", schema.ErrGolemBroken, golem.Symbol)
+	}
+	return nil
+}
+
+// Spend mana to restore the golem to full health, drawing first from the ritual circle where it stands
+// The ritual's mana cost is per point restored
+// Returns the mana spent
+func RepairGolem(userData *schema.User, golemIndex int, ritual schema.Ritual) (float64, error) {
+	golem := &userData.Golems[golemIndex]
+	missing := golem.MaxHealth - golem.Health
+	if missing <= 0 {
+		return 0, fmt.Errorf("%w: %s already has full health", schema.ErrRequirementsNotMet, golem.Symbol)
+	}
+	cost := math.Ceil(float64(missing) * ritual.ManaCost)
+	spendErr := spendRitualMana(userData, golem.LocationSymbol, cost)
+	if spendErr != nil {
+		return 0, spendErr
+	}
+	golem.Health = golem.MaxHealth
+	return cost, nil
+}
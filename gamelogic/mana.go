@@ -4,35 +4,114 @@ package gamelogic
 import (
 	"fmt"
 	"math"
-	"net/http"
-	"time"
+	"sort"
+	"strings"
 
-	"github.com/brct-james/guild-golems/log"
-	"github.com/brct-james/guild-golems/responses"
 	"github.com/brct-james/guild-golems/schema"
 )
 
-// Attempt to do something using mana, return if successful and the new mana value
-func TryManaPurchase(w http.ResponseWriter, mana float64, manaCost float64) (bool, float64) {
-	log.Debug.Println(log.Cyan("-- Begin TryManaPurchase --"))
-	if mana < manaCost {
-		// fail state, not enough mana
-		lowManaMsg := fmt.Sprintf("Have %v but Requires %v", mana, manaCost)
-		responses.SendRes(w, responses.Not_Enough_Mana, nil, lowManaMsg)
-		log.Debug.Println(log.Cyan("-- End TryManaPurchase --"))
-		return false, mana
-	}
-	log.Debug.Println(log.Cyan("-- End TryManaPurchase --"))
-	return true, mana-manaCost
+// Get how the user's mana cap and regen are made up at now: base values, bought upgrades, active buffs and invokers
+func GetManaBreakdown(userData schema.User, now int64) (schema.ManaBreakdown) {
+	breakdown := schema.ManaBreakdown{
+		BaseCap: schema.Base_Mana_Cap,
+		BaseRegen: schema.Base_Mana_Regen,
+		InvokerRegen: make(map[string]float64),
+	}
+	for upgradeSymbol, level := range userData.ManaUpgrades {
+		upgrade, ok := schema.ManaUpgrades[upgradeSymbol]
+		if !ok {
+			// Upgrades removed from json since the user bought them no longer count
+			continue
+		}
+		switch upgrade.Stat {
+		case "mana_cap":
+			breakdown.UpgradeCap += upgrade.AmountPerLevel * float64(level)
+		case "mana_regen":
+			breakdown.UpgradeRegen += upgrade.AmountPerLevel * float64(level)
+		}
+	}
+	for _, buff := range userData.Buffs {
+		if buff.Stat == "mana_regen" && buff.ExpiresAt > now {
+			breakdown.BuffRegen += buff.Amount
+		}
+	}
+	invokerTotal := 0.0
+	for _, invoker := range schema.FilterGolemListByStatus(schema.FilterGolemListByArchetype(userData.Golems, "invoker"), "invoking") {
+		regen := GetInvokerManaRegen(invoker)
+		breakdown.InvokerRegen[invoker.LocationSymbol] += regen
+		invokerTotal += regen
+	}
+	breakdown.ManaCap = breakdown.BaseCap + breakdown.UpgradeCap
+	breakdown.TotalRegen = breakdown.BaseRegen + breakdown.UpgradeRegen + breakdown.BuffRegen + invokerTotal
+	return breakdown
 }
 
-// Update mana value based on time since last update, return the updated userData
-func CalculateManaRegen(userData schema.User) (schema.User) {
-	log.Debug.Println(log.Cyan("-- Begin CalculateManaRegen --"))
-	secondsSinceTick := time.Since(time.Unix(userData.LastManaTick, 0)).Seconds()
-	numInvokers := len(schema.FilterGolemListByStatus(schema.FilterGolemListByArchetype(userData.Golems, "invoker"), "invoking"))
-	userData.Mana = math.Min(userData.ManaCap, userData.Mana + (secondsSinceTick * (userData.ManaRegen + (float64(numInvokers)/2))))
-	userData.LastManaTick = time.Now().Unix()
-	log.Debug.Println(log.Cyan("-- End CalculateManaRegen --"))
+// Set the user's stored mana cap and regen from the base values and their upgrades
+func applyManaBreakdown(userData *schema.User, breakdown schema.ManaBreakdown) {
+	userData.ManaCap = breakdown.ManaCap
+	userData.ManaRegen = breakdown.BaseRegen + breakdown.UpgradeRegen
+}
+
+// Update mana value based on time from the last mana tick to now, return the updated userData
+// Base and upgrade regen flow into the user's mana, invokers fill the ritual circle at their locale first and anything past its cap flows on
+// Invokers regenerate at the rate of their current status, so call this before any status change
+func CalculateManaRegen(userData schema.User, now int64) (schema.User) {
+	breakdown := GetManaBreakdown(userData, userData.LastManaTick)
+	applyManaBreakdown(&userData, breakdown)
+	secondsSinceTick := float64(now - userData.LastManaTick)
+	if secondsSinceTick <= 0 {
+		return userData
+	}
+	// Sorted so the floating point sum of overflow is the same every replay
+	localeSymbols := make([]string, 0, len(breakdown.InvokerRegen))
+	for localeSymbol := range breakdown.InvokerRegen {
+		localeSymbols = append(localeSymbols, localeSymbol)
+	}
+	sort.Strings(localeSymbols)
+	if userData.RitualCircles == nil {
+		userData.RitualCircles = make(map[string]schema.RitualCircle)
+	}
+	overflow := 0.0
+	for _, localeSymbol := range localeSymbols {
+		circle, ok := userData.RitualCircles[localeSymbol]
+		if !ok {
+			circle = schema.RitualCircle{LocaleSymbol: localeSymbol}
+		}
+		produced := secondsSinceTick * breakdown.InvokerRegen[localeSymbol]
+		filled := math.Min(produced, math.Max(0, Ritual_Circle_Mana_Cap - circle.Mana))
+		circle.Mana += filled
+		overflow += produced - filled
+		userData.RitualCircles[localeSymbol] = circle
+	}
+	// Buffs are counted only for the part of the interval they were active, rather than the snapshot in the breakdown
+	buffRegen := getBuffTotal(userData, "mana_regen", userData.LastManaTick, now)
+	userData.Mana = math.Min(userData.ManaCap, userData.Mana + (secondsSinceTick * userData.ManaRegen) + buffRegen + overflow)
+	userData.LastManaTick = now
 	return userData
-}
\ No newline at end of file
+}
+
+// Count the invoking invokers at localeSymbol
+func CountInvokersAtLocale(userData schema.User, localeSymbol string) int {
+	count := 0
+	for _, invoker := range schema.FilterGolemListByStatus(schema.FilterGolemListByArchetype(userData.Golems, "invoker"), "invoking") {
+		if strings.EqualFold(invoker.LocationSymbol, localeSymbol) {
+			count++
+		}
+	}
+	return count
+}
+
+// Spend cost mana on a ritual at localeSymbol, drawing from the locale's ritual circle before the user's own mana
+func spendRitualMana(userData *schema.User, localeSymbol string, cost float64) error {
+	circle := userData.RitualCircles[localeSymbol]
+	if circle.Mana + userData.Mana < cost {
+		return fmt.Errorf("%w: have %v in the circle at %s and %v of your own but this costs %v", schema.ErrInsufficientMana, circle.Mana, localeSymbol, userData.Mana, cost)
+	}
+	fromCircle := math.Min(circle.Mana, cost)
+	if fromCircle > 0 {
+		circle.Mana -= fromCircle
+		userData.RitualCircles[localeSymbol] = circle
+	}
+	userData.Mana -= cost - fromCircle
+	return nil
+}
@@ -0,0 +1,71 @@
+// Package gamelogic provides functions for game logic
+package gamelogic
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/brct-james/guild-golems/schema"
+)
+
+// Get the cost of buying level+1 of upgrade, each level costs CostGrowth times the one before, rounded up
+func GetManaUpgradeCost(upgrade schema.ManaUpgrade, level int) (schema.ManaUpgradeCost) {
+	growth := math.Pow(upgrade.CostGrowth, float64(level))
+	cost := schema.ManaUpgradeCost{
+		ManaCost: math.Ceil(upgrade.ManaCost * growth),
+		CoinCost: uint64(math.Ceil(float64(upgrade.CoinCost) * growth)),
+		ResourceCosts: make([]schema.RecipeComponent, 0, len(upgrade.ResourceCosts)),
+	}
+	for _, resourceCost := range upgrade.ResourceCosts {
+		cost.ResourceCosts = append(cost.ResourceCosts, schema.RecipeComponent{
+			ResourceSymbol: resourceCost.ResourceSymbol,
+			Quantity: int(math.Ceil(float64(resourceCost.Quantity) * growth)),
+		})
+	}
+	return cost
+}
+
+// Get the user's level of every mana upgrade and the cost of its next level, sorted by symbol
+func GetManaUpgradeStatuses(userData schema.User) ([]schema.ManaUpgradeStatus) {
+	symbols := make([]string, 0, len(schema.ManaUpgrades))
+	for symbol := range schema.ManaUpgrades {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	statuses := make([]schema.ManaUpgradeStatus, 0, len(symbols))
+	for _, symbol := range symbols {
+		statuses = append(statuses, GetManaUpgradeStatus(userData, schema.ManaUpgrades[symbol]))
+	}
+	return statuses
+}
+
+// Get the user's level of upgrade and the cost of its next level
+func GetManaUpgradeStatus(userData schema.User, upgrade schema.ManaUpgrade) (schema.ManaUpgradeStatus) {
+	status := schema.ManaUpgradeStatus{ManaUpgrade: upgrade, Level: userData.ManaUpgrades[upgrade.Symbol], NextCost: nil}
+	if status.Level < upgrade.MaxLevel {
+		nextCost := GetManaUpgradeCost(upgrade, status.Level)
+		status.NextCost = &nextCost
+	}
+	return status
+}
+
+// Pay for the next level of upgrade, resource costs are taken from the inventory at localeSymbol
+// Mana must already be regenerated up to now as the new cap and regen apply from the purchase on. Returns the level reached
+func BuyManaUpgrade(userData *schema.User, upgrade schema.ManaUpgrade, localeSymbol string) (int, error) {
+	level := userData.ManaUpgrades[upgrade.Symbol]
+	if level >= upgrade.MaxLevel {
+		return level, fmt.Errorf("%w: %s is already at its max level %d", schema.ErrRequirementsNotMet, upgrade.Symbol, upgrade.MaxLevel)
+	}
+	cost := GetManaUpgradeCost(upgrade, level)
+	payErr := payCosts(userData, upgrade.Symbol, cost.ManaCost, cost.CoinCost, cost.ResourceCosts, localeSymbol)
+	if payErr != nil {
+		return level, payErr
+	}
+	if userData.ManaUpgrades == nil {
+		userData.ManaUpgrades = make(map[string]int)
+	}
+	userData.ManaUpgrades[upgrade.Symbol] = level + 1
+	applyManaBreakdown(userData, GetManaBreakdown(*userData, userData.LastManaTick))
+	return level + 1, nil
+}
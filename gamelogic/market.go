@@ -0,0 +1,193 @@
+// Package gamelogic provides functions for game logic
+package gamelogic
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/brct-james/guild-golems/rdb"
+	"github.com/brct-james/guild-golems/schema"
+)
+
+// Get the unit price of a listing when the market holds stock units
+func CalculateListingPrice(listing schema.MarketListing, stock float64) float64 {
+	if listing.BaseStock <= 0 || listing.Elasticity == 0 {
+		return listing.BasePrice
+	}
+	return listing.BasePrice * math.Pow(listing.BaseStock/math.Max(stock, 1), listing.Elasticity)
+}
+
+// Price players pay for a unit at the given base price, rounded up so buying never undercuts the curve
+func calculateBuyQuote(listing schema.MarketListing, price float64) uint64 {
+	return uint64(math.Max(1, math.Ceil(price*(1+listing.Spread)-1e-9)))
+}
+
+// Price players receive for a unit at the given base price, rounded down so selling never exceeds the curve
+func calculateSellQuote(listing schema.MarketListing, price float64) uint64 {
+	return uint64(math.Max(0, math.Floor(price*(1-listing.Spread)+1e-9)))
+}
+
+// Refresh the BuyPrice and SellPrice quotes of a listing from its current stock
+func updateListingQuotes(listing *schema.MarketListing) {
+	listing.BuyPrice = calculateBuyQuote(*listing, CalculateListingPrice(*listing, listing.Stock))
+	listing.SellPrice = calculateSellQuote(*listing, CalculateListingPrice(*listing, listing.Stock+1))
+}
+
+// Drift the stock of every listing toward its base stock based on time from last drift tick to now, return the updated market
+// Only drift inside Market_update_in_db, as DriftAllMarkets and TradeAtMarket do, or a concurrent drift or trade is lost
+func CalculateMarketDrift(market schema.Market, now int64) (schema.Market) {
+	secondsSinceTick := float64(now - market.LastDriftTick)
+	for symbol, listing := range market.Listings {
+		if listing.DriftHalfLife > 0 && secondsSinceTick > 0 {
+			listing.Stock = listing.BaseStock + (listing.Stock-listing.BaseStock)*math.Pow(0.5, secondsSinceTick/listing.DriftHalfLife)
+		}
+		updateListingQuotes(&listing)
+		market.Listings[symbol] = listing
+	}
+	market.LastDriftTick = now
+	return market
+}
+
+// Atomically drift every market up to now, so stock recovers at markets nobody is trading with
+func DriftAllMarkets(wdb rdb.Database, now int64) error {
+	markets, marketsErr := schema.Market_get_all_from_db(wdb)
+	if marketsErr != nil {
+		return marketsErr
+	}
+	for symbol := range markets {
+		market_path := fmt.Sprintf(".%s", symbol)
+		updateErr := schema.Market_update_in_db(wdb, market_path, func(market schema.Market) (schema.Market, error) {
+			return CalculateMarketDrift(market, now), nil
+		})
+		if updateErr != nil {
+			return fmt.Errorf("could not drift market %s: %w", symbol, updateErr)
+		}
+	}
+	return nil
+}
+
+// Atomically drift the market at the merchant's locale and buy or sell quantity of resource there by action
+// Drift and trade are retried on fresh market data until they commit, userData is only updated once they have
+func TradeAtMarket(wdb rdb.Database, userData *schema.User, merchantSymbol string, locationSymbol string, action string, resource schema.Resource, quantity int, now int64) (schema.MarketTransaction, error) {
+	var transaction schema.MarketTransaction
+	var tradedUser schema.User
+	market_path := fmt.Sprintf(".%s", locationSymbol)
+	updateErr := schema.Market_update_in_db(wdb, market_path, func(market schema.Market) (schema.Market, error) {
+		// A conflicting write retries this, so trade against a fresh copy of the user each attempt
+		attemptUser, copyErr := schema.CopyUser(*userData)
+		if copyErr != nil {
+			return market, copyErr
+		}
+		market = CalculateMarketDrift(market, now)
+		var tradeErr error
+		if action == "buy" {
+			transaction, tradeErr = TryMarketBuy(&attemptUser, merchantSymbol, &market, resource, quantity)
+		} else {
+			transaction, tradeErr = TryMarketSell(&attemptUser, merchantSymbol, &market, resource.Symbol, quantity)
+		}
+		if tradeErr != nil {
+			return market, tradeErr
+		}
+		tradedUser = attemptUser
+		return market, nil
+	})
+	if updateErr != nil {
+		return transaction, updateErr
+	}
+	*userData = tradedUser
+	return transaction, nil
+}
+
+// Atomically undo the stock change of transaction at its market, for when the user's side of the trade could not be saved
+// Quotes are refreshed from the restored stock, drift applied since is kept
+func RevertMarketTrade(wdb rdb.Database, transaction schema.MarketTransaction) error {
+	market_path := fmt.Sprintf(".%s", transaction.LocaleSymbol)
+	return schema.Market_update_in_db(wdb, market_path, func(market schema.Market) (schema.Market, error) {
+		listing, ok := market.Listings[transaction.ResourceSymbol]
+		if !ok {
+			return market, fmt.Errorf("%w: %s at %s", schema.ErrResourceNotTraded, transaction.ResourceSymbol, market.Symbol)
+		}
+		if transaction.Action == "buy" {
+			listing.Stock += float64(transaction.Quantity)
+		} else {
+			listing.Stock = math.Max(0, listing.Stock-float64(transaction.Quantity))
+		}
+		updateListingQuotes(&listing)
+		market.Listings[transaction.ResourceSymbol] = listing
+		return market, nil
+	})
+}
+
+// Buy quantity of resource from market into the user's inventory at the market's locale, paying coins
+// Each unit is priced at the stock remaining before it is bought, market is updated in place
+func TryMarketBuy(userData *schema.User, merchantSymbol string, market *schema.Market, resource schema.Resource, quantity int) (schema.MarketTransaction, error) {
+	if quantity <= 0 {
+		return schema.MarketTransaction{}, fmt.Errorf("cannot buy non-positive quantity %d of %s", quantity, resource.Symbol)
+	}
+	listing, ok := market.Listings[resource.Symbol]
+	if !ok {
+		return schema.MarketTransaction{}, fmt.Errorf("%w: %s at %s", schema.ErrResourceNotTraded, resource.Symbol, market.Symbol)
+	}
+	if listing.Stock < float64(quantity) {
+		return schema.MarketTransaction{}, fmt.Errorf("%w: market at %s has %d %s but requires %d", schema.ErrInsufficientQuantity, market.Symbol, int(listing.Stock), resource.Symbol, quantity)
+	}
+	totalPrice := uint64(0)
+	for n := 0; n < quantity; n++ {
+		totalPrice += calculateBuyQuote(listing, CalculateListingPrice(listing, listing.Stock-float64(n)))
+	}
+	if userData.Coins < totalPrice {
+		return schema.MarketTransaction{}, fmt.Errorf("%w: have %d but requires %d", schema.ErrInsufficientCoins, userData.Coins, totalPrice)
+	}
+	addErr := DepositToLocale(userData, market.Symbol, resource, quantity)
+	if addErr != nil {
+		return schema.MarketTransaction{}, addErr
+	}
+	userData.Coins -= totalPrice
+	listing.Stock -= float64(quantity)
+	updateListingQuotes(&listing)
+	market.Listings[resource.Symbol] = listing
+	return schema.MarketTransaction{
+		MerchantSymbol: merchantSymbol,
+		LocaleSymbol: market.Symbol,
+		ResourceSymbol: resource.Symbol,
+		Action: "buy",
+		Quantity: quantity,
+		UnitPrice: totalPrice / uint64(quantity),
+		TotalPrice: totalPrice,
+		Coins: userData.Coins,
+	}, nil
+}
+
+// Sell quantity of resourceSymbol from the user's inventory at the market's locale, receiving coins
+// Each unit is priced at the stock after it is sold, market is updated in place
+func TryMarketSell(userData *schema.User, merchantSymbol string, market *schema.Market, resourceSymbol string, quantity int) (schema.MarketTransaction, error) {
+	if quantity <= 0 {
+		return schema.MarketTransaction{}, fmt.Errorf("cannot sell non-positive quantity %d of %s", quantity, resourceSymbol)
+	}
+	listing, ok := market.Listings[resourceSymbol]
+	if !ok {
+		return schema.MarketTransaction{}, fmt.Errorf("%w: %s at %s", schema.ErrResourceNotTraded, resourceSymbol, market.Symbol)
+	}
+	_, removeErr := schema.RemoveFromLocationInventory(userData, market.Symbol, resourceSymbol, quantity)
+	if removeErr != nil {
+		return schema.MarketTransaction{}, removeErr
+	}
+	totalPrice := uint64(0)
+	for n := 1; n <= quantity; n++ {
+		totalPrice += calculateSellQuote(listing, CalculateListingPrice(listing, listing.Stock+float64(n)))
+	}
+	userData.Coins += totalPrice
+	listing.Stock += float64(quantity)
+	updateListingQuotes(&listing)
+	market.Listings[resourceSymbol] = listing
+	return schema.MarketTransaction{
+		MerchantSymbol: merchantSymbol,
+		LocaleSymbol: market.Symbol,
+		ResourceSymbol: resourceSymbol,
+		Action: "sell",
+		Quantity: quantity,
+		UnitPrice: totalPrice / uint64(quantity),
+		TotalPrice: totalPrice,
+		Coins: userData.Coins,
+	}, nil
+}
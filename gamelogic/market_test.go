@@ -0,0 +1,111 @@
+package gamelogic
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/brct-james/guild-golems/schema"
+)
+
+// Get an ore listing priced at 10 when the market holds its base stock of 100, with the given stock and drift half-life
+func newTestListing(stock float64, driftHalfLife float64) (schema.MarketListing) {
+	return schema.MarketListing{
+		ResourceSymbol: "ore",
+		BasePrice: 10,
+		BaseStock: 100,
+		Stock: stock,
+		Elasticity: 1,
+		Spread: 0.1,
+		DriftHalfLife: driftHalfLife,
+	}
+}
+
+// Get a market last drifted at testStart holding listing as its only listing
+func newTestMarket(listing schema.MarketListing) (schema.Market) {
+	market := schema.Market{LastDriftTick: testStart.Unix(), Listings: map[string]schema.MarketListing{listing.ResourceSymbol: listing}}
+	market.Symbol = "A-G"
+	return market
+}
+
+func TestCalculateListingPriceFollowsStock(t *testing.T) {
+	tests := []struct {
+		name string
+		modify func(*schema.MarketListing)
+		stock float64
+		expected float64
+	}{
+		{"zero stock is priced as a single unit", nil, 0, 1000},
+		{"base stock is the base price", nil, 100, 10},
+		{"below base stock is dearer", nil, 50, 20},
+		{"over base stock is cheaper", nil, 200, 5},
+		{"elasticity steepens the curve", func(l *schema.MarketListing) { l.Elasticity = 2 }, 200, 2.5},
+		{"no elasticity is always the base price", func(l *schema.MarketListing) { l.Elasticity = 0 }, 0, 10},
+		{"no base stock is always the base price", func(l *schema.MarketListing) { l.BaseStock = 0 }, 200, 10},
+	}
+	for _, test := range tests {
+		listing := newTestListing(0, 0)
+		if test.modify != nil {
+			test.modify(&listing)
+		}
+		price := CalculateListingPrice(listing, test.stock)
+		if math.Abs(price-test.expected) > 1e-9 {
+			t.Fatalf("%s: expected price %v at stock %v, got %v", test.name, test.expected, test.stock, price)
+		}
+	}
+}
+
+func TestCalculateMarketDriftMovesStockTowardBase(t *testing.T) {
+	tests := []struct {
+		name string
+		stock float64
+		driftHalfLife float64
+		elapsed time.Duration
+		expected float64
+	}{
+		{"no time passed", 20, 60, 0, 20},
+		{"one half-life from below", 20, 60, 60 * time.Second, 60},
+		{"two half-lives from below", 20, 60, 120 * time.Second, 80},
+		{"two half-lives from above", 180, 60, 120 * time.Second, 120},
+		{"at base stock", 100, 60, 600 * time.Second, 100},
+		{"drift disabled", 20, 0, 600 * time.Second, 20},
+	}
+	for _, test := range tests {
+		clock := &ManualClock{Time: testStart}
+		market := newTestMarket(newTestListing(test.stock, test.driftHalfLife))
+		clock.Advance(test.elapsed)
+		market = CalculateMarketDrift(market, clock.Now().Unix())
+		listing := market.Listings["ore"]
+		if math.Abs(listing.Stock-test.expected) > 1e-9 {
+			t.Fatalf("%s: expected stock %v after %v, got %v", test.name, test.expected, test.elapsed, listing.Stock)
+		}
+		if market.LastDriftTick != clock.Now().Unix() {
+			t.Fatalf("%s: expected last drift tick %d, got %d", test.name, clock.Now().Unix(), market.LastDriftTick)
+		}
+	}
+}
+
+func TestCalculateMarketDriftCompoundsAcrossTicks(t *testing.T) {
+	clock := &ManualClock{Time: testStart}
+	market := newTestMarket(newTestListing(20, 60))
+	// Drifting every 30 seconds must end where a single drift over the whole time would
+	for i := 0; i < 4; i++ {
+		clock.Advance(30 * time.Second)
+		market = CalculateMarketDrift(market, clock.Now().Unix())
+	}
+	if stock := market.Listings["ore"].Stock; math.Abs(stock-80) > 1e-9 {
+		t.Fatalf("expected stock 80 after two half-lives in four ticks, got %v", stock)
+	}
+}
+
+func TestCalculateMarketDriftRefreshesQuotes(t *testing.T) {
+	clock := &ManualClock{Time: testStart}
+	market := newTestMarket(newTestListing(50, 60))
+	clock.Advance(time.Hour)
+	market = CalculateMarketDrift(market, clock.Now().Unix())
+	listing := market.Listings["ore"]
+	// Back at base stock, buying pays the base price plus the spread and selling one more unit gets 10 * 100/101 less the spread
+	if listing.BuyPrice != 11 || listing.SellPrice != 8 {
+		t.Fatalf("expected buy price 11 and sell price 8 at base stock, got %d and %d", listing.BuyPrice, listing.SellPrice)
+	}
+}
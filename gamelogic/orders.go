@@ -0,0 +1,338 @@
+// Package gamelogic provides functions for game logic
+package gamelogic
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brct-james/guild-golems/log"
+	"github.com/brct-james/guild-golems/rdb"
+	"github.com/brct-james/guild-golems/schema"
+)
+
+// Actions that complete as soon as they are executed rather than changing the golem's status
+var instantOrderActions = map[string]bool{
+	"load": true,
+	"unload": true,
+}
+
+// Get a string instruction, erroring if it is missing or the wrong type
+func getStringInstruction(order schema.GolemOrder, key string) (string, error) {
+	raw, ok := order.Instructions[key]
+	value, isString := raw.(string)
+	if !ok || !isString {
+		return "", fmt.Errorf("%w: '%s' key required for '%s'", schema.ErrInvalidOrder, key, order.Action)
+	}
+	return value, nil
+}
+
+// Convert a {"SYMBOL": quantity} object from order instructions into resource quantities
+func parseResourceQuantities(raw interface{}) (map[string]int, error) {
+	rawMap, ok := raw.(map[string]interface{})
+	if !ok || len(rawMap) < 1 {
+		return nil, fmt.Errorf("%w: 'resources' must be an object of the form {\"SYMBOL\": quantity}", schema.ErrInvalidOrder)
+	}
+	resourceQuantities := make(map[string]int)
+	for symbol, rawQuantity := range rawMap {
+		quantity, isNumber := rawQuantity.(float64)
+		if !isNumber || quantity != float64(int(quantity)) || quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity for %s must be a positive whole number", schema.ErrInvalidOrder, symbol)
+		}
+		resourceQuantities[symbol] = int(quantity)
+	}
+	return resourceQuantities, nil
+}
+
+// Get the route symbols to travel from the 'route' or 'path' instruction
+func getRouteSymbolsInstruction(order schema.GolemOrder) ([]string, error) {
+	if rawPath, ok := order.Instructions["path"]; ok {
+		rawRoutes, isList := rawPath.([]interface{})
+		if !isList || len(rawRoutes) < 1 {
+			return nil, fmt.Errorf("%w: 'path' must be a list of route symbols", schema.ErrInvalidOrder)
+		}
+		routeSymbols := make([]string, 0, len(rawRoutes))
+		for _, rawRoute := range rawRoutes {
+			routeSymbol, isString := rawRoute.(string)
+			if !isString {
+				return nil, fmt.Errorf("%w: 'path' must be a list of route symbols", schema.ErrInvalidOrder)
+			}
+			routeSymbols = append(routeSymbols, routeSymbol)
+		}
+		return routeSymbols, nil
+	}
+	routeSymbol, routeErr := getStringInstruction(order, "route")
+	if routeErr != nil {
+		return nil, fmt.Errorf("%w: 'route' or 'path' key required for '%s'", schema.ErrInvalidOrder, order.Action)
+	}
+	return []string{routeSymbol}, nil
+}
+
+// Resolve the route symbols into a path starting at the golem's locale, checking the first route leaves from there and any region entry requirements
+func getOrderPath(userData schema.User, golem schema.Golem, routeSymbols []string, wdb rdb.Database) ([]schema.Route, error) {
+	locale, localeErr := schema.Locale_get_from_db(wdb, fmt.Sprintf(".%s", golem.LocationSymbol))
+	if localeErr != nil {
+		return nil, fmt.Errorf("%w: could not get locale %s: %v", schema.ErrWorldDataUnavailable, golem.LocationSymbol, localeErr)
+	}
+	routeListed := false
+	for _, routeSymbol := range locale.RouteSymbols {
+		if strings.EqualFold(routeSymbol, routeSymbols[0]) {
+			routeListed = true
+			routeSymbols[0] = routeSymbol
+			break
+		}
+	}
+	if !routeListed {
+		return nil, fmt.Errorf("%w: %s does not leave from %s", schema.ErrRouteUnavailable, routeSymbols[0], golem.LocationSymbol)
+	}
+	routes, routesErr := schema.Route_get_all_from_db(wdb)
+	if routesErr != nil {
+		return nil, fmt.Errorf("%w: could not get routes: %v", schema.ErrWorldDataUnavailable, routesErr)
+	}
+	path, pathErr := ResolvePath(routes, golem.LocationSymbol, routeSymbols)
+	if pathErr != nil {
+		return nil, pathErr
+	}
+	regions, regionsErr := schema.Region_get_all_from_db(wdb)
+	if regionsErr != nil {
+		return nil, fmt.Errorf("%w: could not get regions: %v", schema.ErrWorldDataUnavailable, regionsErr)
+	}
+	entryErr := CheckRegionEntryRequirements(userData, regions, path)
+	if entryErr != nil {
+		return nil, entryErr
+	}
+	return path, nil
+}
+
+// Get the resource node if it is listed at the golem's locale
+func getOrderResourceNode(golem schema.Golem, nodeSymbol string, wdb rdb.Database) (schema.ResourceNode, error) {
+	locale, localeErr := schema.Locale_get_from_db(wdb, fmt.Sprintf(".%s", golem.LocationSymbol))
+	if localeErr != nil {
+		return schema.ResourceNode{}, fmt.Errorf("%w: could not get locale %s: %v", schema.ErrWorldDataUnavailable, golem.LocationSymbol, localeErr)
+	}
+	for _, localeNodeSymbol := range locale.ResourceNodeSymbols {
+		if strings.EqualFold(localeNodeSymbol, nodeSymbol) {
+			node, nodeErr := schema.ResourceNode_get_from_db(wdb, fmt.Sprintf(".%s", localeNodeSymbol))
+			if nodeErr != nil {
+				return schema.ResourceNode{}, fmt.Errorf("%w: could not get resource node %s: %v", schema.ErrWorldDataUnavailable, localeNodeSymbol, nodeErr)
+			}
+			return node, nil
+		}
+	}
+	return schema.ResourceNode{}, fmt.Errorf("%w: %s is not at %s", schema.ErrResourceNodeUnavailable, nodeSymbol, golem.LocationSymbol)
+}
+
+// Check order is well formed and allowed for the golem's archetype, without executing it
+func ValidateGolemOrder(golem schema.Golem, order schema.GolemOrder) error {
+	if instantOrderActions[order.Action] {
+		return nil
+	}
+	if _, ok := schema.GolemStatuses[order.Action]; !ok {
+		return fmt.Errorf("%w: unknown action %s", schema.ErrInvalidOrder, order.Action)
+	}
+	isAllowed, archetypeErr := schema.IsStatusAllowedForArchetype(golem.Archetype, order.Action)
+	if archetypeErr != nil {
+		return archetypeErr
+	}
+	if !isAllowed {
+		return fmt.Errorf("%w: %s cannot be %s", schema.ErrStatusNotAllowed, golem.Archetype, order.Action)
+	}
+	return nil
+}
+
+// Carry out order for the golem starting at startTime, e.g. setting it traveling or unloading its cargo
+// Returns an error wrapping a schema sentinel error if the order cannot be started
+func ExecuteGolemOrder(userData *schema.User, golemIndex int, order schema.GolemOrder, wdb rdb.Database, startTime time.Time) error {
+	golem := &userData.Golems[golemIndex]
+	validateErr := ValidateGolemOrder(*golem, order)
+	if validateErr != nil {
+		return validateErr
+	}
+	if order.Action != "idle" && !instantOrderActions[order.Action] {
+		healthErr := CheckGolemHealth(*golem)
+		if healthErr != nil {
+			return healthErr
+		}
+		energyErr := CheckGolemEnergy(*golem)
+		if energyErr != nil {
+			return energyErr
+		}
+	}
+	switch order.Action {
+	case "idle":
+		golem.Status = "idle"
+		golem.IdleSince = startTime.Unix()
+		return nil
+	case "invoking":
+		golem.Status = "invoking"
+		return nil
+	case "harvesting":
+		nodeSymbol, instructionErr := getStringInstruction(order, "resource_node")
+		if instructionErr != nil {
+			return instructionErr
+		}
+		// Optionally deposit into the locale inventory when cargo is full rather than stopping
+		autoDeposit := false
+		if rawAutoDeposit, ok := order.Instructions["auto_deposit"]; ok {
+			autoDepositBool, isBool := rawAutoDeposit.(bool)
+			if !isBool {
+				return fmt.Errorf("%w: 'auto_deposit' must be a boolean", schema.ErrInvalidOrder)
+			}
+			autoDeposit = autoDepositBool
+		}
+		node, nodeErr := getOrderResourceNode(*golem, nodeSymbol, wdb)
+		if nodeErr != nil {
+			return nodeErr
+		}
+		StartHarvest(userData, golemIndex, node, autoDeposit, startTime)
+		return nil
+	case "traveling":
+		routeSymbols, instructionErr := getRouteSymbolsInstruction(order)
+		if instructionErr != nil {
+			return instructionErr
+		}
+		path, pathErr := getOrderPath(*userData, *golem, routeSymbols, wdb)
+		if pathErr != nil {
+			return pathErr
+		}
+		return StartGolemPath(userData, golemIndex, path, "traveling", startTime)
+	case "delivering":
+		routeSymbol, instructionErr := getStringInstruction(order, "route")
+		if instructionErr != nil {
+			return instructionErr
+		}
+		resourceQuantities, parseErr := parseResourceQuantities(order.Instructions["resources"])
+		if parseErr != nil {
+			return parseErr
+		}
+		path, pathErr := getOrderPath(*userData, *golem, []string{routeSymbol}, wdb)
+		if pathErr != nil {
+			return pathErr
+		}
+		// Check the route is affordable first so a failed departure never leaves the delivery loaded
+		costErr := CheckRouteCosts(*userData, path)
+		if costErr != nil {
+			return costErr
+		}
+		// Pick up resources from the origin locale inventory, they are deposited at the destination by CompleteTravelHop
+		loadErr := LoadGolemCargo(userData, golemIndex, resourceQuantities)
+		if loadErr != nil {
+			return loadErr
+		}
+		return StartGolemPath(userData, golemIndex, path, "delivering", startTime)
+	case "crafting":
+		recipeSymbol, instructionErr := getStringInstruction(order, "recipe")
+		if instructionErr != nil {
+			return instructionErr
+		}
+		recipe, recipeErr := schema.Recipe_get_from_db(wdb, fmt.Sprintf(".%s", recipeSymbol))
+		if recipeErr != nil {
+			return fmt.Errorf("%w: %s", schema.ErrNoSuchRecipe, recipeSymbol)
+		}
+		// Outputs are deposited by CompleteCraft
+		return StartCraft(userData, golemIndex, recipe, startTime)
+	case "building":
+		blueprintSymbol, instructionErr := getStringInstruction(order, "blueprint")
+		if instructionErr != nil {
+			return instructionErr
+		}
+		blueprint, blueprintErr := schema.Blueprint_get_from_db(wdb, fmt.Sprintf(".%s", blueprintSymbol))
+		if blueprintErr != nil {
+			return fmt.Errorf("%w: %s", schema.ErrNoSuchBlueprint, blueprintSymbol)
+		}
+		// Join or start the construction site, progress is calculated lazily by CalculateBuildingProgress
+		return StartBuilding(userData, golemIndex, blueprint, startTime)
+	case "load":
+		resourceQuantities, parseErr := parseResourceQuantities(order.Instructions["resources"])
+		if parseErr != nil {
+			return parseErr
+		}
+		return LoadGolemCargo(userData, golemIndex, resourceQuantities)
+	case "unload":
+		// Without resources the entire cargo is unloaded
+		if _, ok := order.Instructions["resources"]; !ok {
+			return UnloadAllGolemCargo(userData, golemIndex)
+		}
+		resourceQuantities, parseErr := parseResourceQuantities(order.Instructions["resources"])
+		if parseErr != nil {
+			return parseErr
+		}
+		return UnloadGolemCargo(userData, golemIndex, resourceQuantities)
+	}
+	return fmt.Errorf("%w: unhandled action %s", schema.ErrInvalidOrder, order.Action)
+}
+
+// Replace the golem's order queue and start the first order at startTime
+// Every order is validated up front, orders other than the first are only checked against world state when they start
+func SetGolemOrders(userData *schema.User, golemIndex int, orders []schema.GolemOrder, repeat bool, wdb rdb.Database, startTime time.Time) error {
+	golem := &userData.Golems[golemIndex]
+	if len(orders) < 1 {
+		return fmt.Errorf("%w: at least one order required", schema.ErrInvalidOrder)
+	}
+	onlyInstant := true
+	for _, order := range orders {
+		onlyInstant = onlyInstant && instantOrderActions[order.Action]
+		if order.Action == "idle" {
+			return fmt.Errorf("%w: 'idle' cannot be queued", schema.ErrInvalidOrder)
+		}
+		validateErr := ValidateGolemOrder(*golem, order)
+		if validateErr != nil {
+			return validateErr
+		}
+	}
+	if repeat && onlyInstant {
+		// Would repeat forever without time passing
+		return fmt.Errorf("%w: a repeating queue needs at least one order that takes time", schema.ErrInvalidOrder)
+	}
+	golem.Orders = orders
+	golem.OrderIndex = 0
+	golem.RepeatOrders = repeat
+	executeErr := ExecuteGolemOrder(userData, golemIndex, orders[0], wdb, startTime)
+	if executeErr != nil {
+		return executeErr
+	}
+	if instantOrderActions[orders[0].Action] {
+		// Instant orders are already complete, move straight on to the next
+		advanceGolemOrders(userData, golemIndex, wdb, startTime)
+	}
+	return nil
+}
+
+// Drop the golem's order queue, leaving its current status as is
+func ClearGolemOrders(golem *schema.Golem) {
+	golem.Orders = make([]schema.GolemOrder, 0)
+	golem.OrderIndex = 0
+	golem.RepeatOrders = false
+}
+
+// Start the golem's next orders from startTime until one changes its status, wrapping around if the queue repeats
+// On failure the queue is cleared and the reason recorded in the golem's event log
+// Returns whether any order was started
+func advanceGolemOrders(userData *schema.User, golemIndex int, wdb rdb.Database, startTime time.Time) (bool) {
+	golem := &userData.Golems[golemIndex]
+	// At most one pass through the queue, so a queue of only instant orders cannot loop forever
+	for executed := 0; executed < len(golem.Orders); executed++ {
+		nextIndex := golem.OrderIndex + 1
+		if nextIndex >= len(golem.Orders) {
+			if !golem.RepeatOrders {
+				schema.AddGolemEvent(golem, startTime.Unix(), "orders_complete", "Finished all orders")
+				ClearGolemOrders(golem)
+				return executed > 0
+			}
+			nextIndex = 0
+		}
+		golem.OrderIndex = nextIndex
+		order := golem.Orders[nextIndex]
+		executeErr := ExecuteGolemOrder(userData, golemIndex, order, wdb, startTime)
+		if executeErr != nil {
+			log.Debug.Printf("Golem %s could not start queued order %s: %v", golem.Symbol, order.Action, executeErr)
+			schema.AddGolemEvent(golem, startTime.Unix(), "order_failed", fmt.Sprintf("Could not %s, orders cleared: %v", order.Action, executeErr))
+			ClearGolemOrders(golem)
+			return executed > 0
+		}
+		if !instantOrderActions[order.Action] {
+			return true
+		}
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+// Package gamelogic provides functions for game logic
+package gamelogic
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/brct-james/guild-golems/schema"
+)
+
+// Get the user's status with every item in the research tree at now, sorted by symbol
+func GetResearchStatuses(userData schema.User, now int64) ([]schema.ResearchStatus) {
+	symbols := make([]string, 0, len(schema.ResearchTree))
+	for symbol := range schema.ResearchTree {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	statuses := make([]schema.ResearchStatus, 0, len(symbols))
+	for _, symbol := range symbols {
+		research := schema.ResearchTree[symbol]
+		status := schema.ResearchStatus{Research: research, Status: "locked", Progress: 0, CompletionTime: 0}
+		switch {
+		case schema.DoesUserKnowRitual(userData, research.RitualSymbol):
+			status.Status = "learned"
+			status.Progress = 1
+		case userData.ActiveResearch.ResearchSymbol == symbol:
+			status.Status = "in-progress"
+			status.CompletionTime = userData.ActiveResearch.CompletionTime
+			duration := float64(userData.ActiveResearch.CompletionTime - userData.ActiveResearch.StartTime)
+			status.Progress = 1
+			if duration > 0 {
+				status.Progress = math.Max(0, math.Min(1, float64(now - userData.ActiveResearch.StartTime)/duration))
+			}
+		case checkResearchPrerequisites(userData, research) == nil:
+			status.Status = "available"
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// Check the user knows every ritual research requires
+func checkResearchPrerequisites(userData schema.User, research schema.Research) error {
+	for _, prerequisite := range research.PrerequisiteRituals {
+		if !schema.DoesUserKnowRitual(userData, prerequisite) {
+			return fmt.Errorf("%w: %s requires knowing %s", schema.ErrRequirementsNotMet, research.Symbol, prerequisite)
+		}
+	}
+	return nil
+}
+
+// Pay for research and start it at startTime, resource costs are taken from the inventory at localeSymbol
+// Only one item may be researched at a time
+func StartResearch(userData *schema.User, research schema.Research, localeSymbol string, startTime int64) error {
+	if schema.DoesUserKnowRitual(*userData, research.RitualSymbol) {
+		return fmt.Errorf("%w: already know %s", schema.ErrRequirementsNotMet, research.RitualSymbol)
+	}
+	if userData.ActiveResearch.ResearchSymbol != "" {
+		return fmt.Errorf("%w: already researching %s", schema.ErrRequirementsNotMet, userData.ActiveResearch.ResearchSymbol)
+	}
+	prerequisiteErr := checkResearchPrerequisites(*userData, research)
+	if prerequisiteErr != nil {
+		return prerequisiteErr
+	}
+	payErr := payCosts(userData, research.Symbol, research.ManaCost, research.CoinCost, research.ResourceCosts, localeSymbol)
+	if payErr != nil {
+		return payErr
+	}
+	userData.ActiveResearch = schema.ResearchProgress{
+		ResearchSymbol: research.Symbol,
+		StartTime: startTime,
+		CompletionTime: startTime + int64(research.ResearchTime),
+	}
+	return nil
+}
+
+// Finish the user's active research, teaching them its ritual
+func CompleteResearch(userData *schema.User) {
+	research, ok := schema.ResearchTree[userData.ActiveResearch.ResearchSymbol]
+	if ok && !schema.DoesUserKnowRitual(*userData, research.RitualSymbol) {
+		userData.KnownRituals = append(userData.KnownRituals, research.RitualSymbol)
+	}
+	userData.ActiveResearch = schema.ResearchProgress{}
+}
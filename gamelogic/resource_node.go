@@ -0,0 +1,85 @@
+// Package gamelogic provides functions for game logic
+package gamelogic
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/brct-james/guild-golems/rdb"
+	"github.com/brct-james/guild-golems/schema"
+)
+
+// Replenish node state based on time since last replenish tick, return the updated state
+func CalculateNodeReplenishment(node schema.ResourceNode, state schema.ResourceNodeState, now int64) (schema.ResourceNodeState) {
+	secondsSinceTick := float64(now - state.LastReplenishTick)
+	if secondsSinceTick > 0 {
+		state.Quantity = math.Min(float64(node.MaxQuantity), state.Quantity + secondsSinceTick*node.ReplenishRate)
+		state.LastReplenishTick = now
+	}
+	return state
+}
+
+// Atomically take up to requested harvests from the node's shared pool, returning how many were granted
+// Infinite nodes always grant the full request, now is the time the node is replenished up to
+func TryDrawFromNode(wdb rdb.Database, node schema.ResourceNode, requested int64, now int64) (int64, error) {
+	if !schema.IsResourceNodeFinite(node) || requested < 1 {
+		return requested, nil
+	}
+	var granted int64
+	node_path := fmt.Sprintf(".%s", node.Symbol)
+	updateErr := schema.ResourceNodeState_update_in_db(wdb, node_path, func(state schema.ResourceNodeState) (schema.ResourceNodeState, error) {
+		// Reset on every attempt, as the update may be retried after a conflict
+		state = CalculateNodeReplenishment(node, state, now)
+		granted = int64(math.Min(float64(requested), math.Floor(state.Quantity)))
+		state.Quantity -= float64(granted)
+		return state, nil
+	})
+	if updateErr != nil {
+		return 0, updateErr
+	}
+	return granted, nil
+}
+
+// Atomically put unused harvests back into the node's shared pool, never exceeding its max quantity
+func ReturnToNode(wdb rdb.Database, node schema.ResourceNode, amount int64, now int64) error {
+	if !schema.IsResourceNodeFinite(node) || amount < 1 {
+		return nil
+	}
+	node_path := fmt.Sprintf(".%s", node.Symbol)
+	return schema.ResourceNodeState_update_in_db(wdb, node_path, func(state schema.ResourceNodeState) (schema.ResourceNodeState, error) {
+		state = CalculateNodeReplenishment(node, state, now)
+		state.Quantity = math.Min(float64(node.MaxQuantity), state.Quantity + float64(amount))
+		return state, nil
+	})
+}
+
+// Get the state of every finite node with replenishment applied up to now, keyed by node symbol
+func GetCurrentNodeStates(wdb rdb.Database, resourcenodes map[string]schema.ResourceNode, now int64) (map[string]schema.ResourceNodeState, error) {
+	states, getErr := schema.ResourceNodeState_get_all_from_db(wdb)
+	if getErr != nil {
+		return states, getErr
+	}
+	for symbol, state := range states {
+		if node, ok := resourcenodes[symbol]; ok {
+			states[symbol] = CalculateNodeReplenishment(node, state, now)
+		}
+	}
+	return states, nil
+}
+
+// Atomically bring every finite node's stored state up to now, so idle nodes refill without anyone harvesting them
+func ReplenishAllNodes(wdb rdb.Database, resourcenodes map[string]schema.ResourceNode, now int64) error {
+	for symbol, node := range resourcenodes {
+		if !schema.IsResourceNodeFinite(node) {
+			continue
+		}
+		node_path := fmt.Sprintf(".%s", symbol)
+		updateErr := schema.ResourceNodeState_update_in_db(wdb, node_path, func(state schema.ResourceNodeState) (schema.ResourceNodeState, error) {
+			return CalculateNodeReplenishment(node, state, now), nil
+		})
+		if updateErr != nil {
+			return fmt.Errorf("could not replenish resource node %s: %w", symbol, updateErr)
+		}
+	}
+	return nil
+}
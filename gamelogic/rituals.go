@@ -0,0 +1,225 @@
+// Package gamelogic provides functions for game logic
+package gamelogic
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/brct-james/guild-golems/rdb"
+	"github.com/brct-james/guild-golems/schema"
+)
+
+// Perform the ritual on target for the user at timestamp, charging mana as its definition describes
+// A golem targeted by the ritual must be at the target locale, a golem in transit cannot be targeted
+func PerformRitual(userData *schema.User, ritual schema.Ritual, target schema.RitualTarget, wdb rdb.Database, timestamp int64) (schema.RitualResult, error) {
+	localeSymbol := target.LocaleSymbol
+	golemIndex := target.GolemIndex
+	result := schema.RitualResult{RitualSymbol: ritual.Symbol, LocaleSymbol: localeSymbol}
+	effect := ritual.Effect
+	if schema.RitualTargetsGolem(ritual) && strings.EqualFold(userData.Golems[golemIndex].LocationSymbol, schema.In_Transit_Location_Symbol) {
+		return result, fmt.Errorf("%w: %s cannot be the target of %s", schema.ErrGolemInTransit, userData.Golems[golemIndex].Symbol, ritual.Symbol)
+	}
+	if schema.RitualTargetsGolem(ritual) && !strings.EqualFold(userData.Golems[golemIndex].LocationSymbol, localeSymbol) {
+		return result, fmt.Errorf("%w: %s is not at %s", schema.ErrRequirementsNotMet, userData.Golems[golemIndex].Symbol, localeSymbol)
+	}
+	invokers := CountInvokersAtLocale(*userData, localeSymbol)
+	if invokers < ritual.RequiredInvokers {
+		return result, fmt.Errorf("%w: %s needs %d invoking at %s but there are %d", schema.ErrNotEnoughInvokers, ritual.Symbol, ritual.RequiredInvokers, localeSymbol, invokers)
+	}
+	// Golem rituals price themselves off the golem, the rest cost their flat mana cost once the effect is known to be possible
+	switch effect.Type {
+	case "upgrade_golem":
+		cost, err := UpgradeGolem(userData, golemIndex, ritual, timestamp)
+		if err != nil {
+			return result, err
+		}
+		result.ManaSpent = cost
+		golem := userData.Golems[golemIndex]
+		result.Golem = &golem
+		return result, nil
+	case "recharge_golem":
+		cost, err := RechargeGolem(userData, golemIndex, ritual)
+		if err != nil {
+			return result, err
+		}
+		result.ManaSpent = cost
+		golem := userData.Golems[golemIndex]
+		result.Golem = &golem
+		return result, nil
+	case "repair_golem":
+		cost, err := RepairGolem(userData, golemIndex, ritual)
+		if err != nil {
+			return result, err
+		}
+		result.ManaSpent = cost
+		golem := userData.Golems[golemIndex]
+		result.Golem = &golem
+		return result, nil
+	case "teleport":
+		path, err := PlanTeleport(*userData, golemIndex, target.DestinationSymbol, wdb)
+		if err != nil {
+			return result, err
+		}
+		cost, err := TeleportGolem(userData, golemIndex, ritual, path, timestamp)
+		if err != nil {
+			return result, err
+		}
+		result.ManaSpent = cost
+		golem := userData.Golems[golemIndex]
+		result.Golem = &golem
+		return result, nil
+	case "learn_ritual":
+		if schema.DoesUserKnowRitual(*userData, effect.RitualSymbol) {
+			return result, fmt.Errorf("%w: already know %s", schema.ErrRequirementsNotMet, effect.RitualSymbol)
+		}
+	case "summon_golem":
+		summonErr := checkSummonLocale(*userData, ritual, localeSymbol, wdb)
+		if summonErr != nil {
+			return result, summonErr
+		}
+	case "buff":
+	default:
+		return result, fmt.Errorf("%w: ritual %s has unknown effect %s", schema.ErrRitualUnimplemented, ritual.Symbol, effect.Type)
+	}
+	spendErr := spendRitualMana(userData, localeSymbol, ritual.ManaCost)
+	if spendErr != nil {
+		return result, spendErr
+	}
+	result.ManaSpent = ritual.ManaCost
+	switch effect.Type {
+	case "summon_golem":
+		newGolemId := NextGolemId(userData, effect.Archetype)
+		newGolemSymbol := fmt.Sprintf("%s-%d", schema.GolemArchetypes[effect.Archetype].Abbreviation, newGolemId)
+		newGolem := schema.NewGolem(newGolemSymbol, effect.Archetype, effect.StartingStatus, GetArchetypeBaseCapacity(effect.Archetype))
+		newGolem.LocationSymbol = localeSymbol
+		newGolem.SummonManaCost = ritual.ManaCost
+		userData.Golems = append(userData.Golems, newGolem)
+		result.Golem = &newGolem
+	case "learn_ritual":
+		userData.KnownRituals = append(userData.KnownRituals, effect.RitualSymbol)
+		result.LearnedRitual = effect.RitualSymbol
+	case "buff":
+		// Recasting a buff refreshes its duration rather than stacking it
+		buff := schema.Buff{RitualSymbol: ritual.Symbol, Stat: effect.Stat, Amount: effect.Amount, ExpiresAt: timestamp + int64(effect.Duration)}
+		buffs := make([]schema.Buff, 0, len(userData.Buffs)+1)
+		for _, existing := range userData.Buffs {
+			if existing.RitualSymbol != ritual.Symbol {
+				buffs = append(buffs, existing)
+			}
+		}
+		userData.Buffs = append(buffs, buff)
+		result.Buff = &buff
+	}
+	return result, nil
+}
+
+// Check a golem may be summoned at localeSymbol, the user must meet the entry requirements of its region
+// Rituals needing no invokers may only summon at Starting_Locale_Symbol or where the user already has a golem
+func checkSummonLocale(userData schema.User, ritual schema.Ritual, localeSymbol string, wdb rdb.Database) error {
+	if ritual.RequiredInvokers == 0 && !strings.EqualFold(localeSymbol, schema.Starting_Locale_Symbol) && !doesUserHaveGolemAtLocale(userData, localeSymbol) {
+		return fmt.Errorf("%w: %s can only summon at %s or where you already have a golem", schema.ErrRequirementsNotMet, ritual.Symbol, schema.Starting_Locale_Symbol)
+	}
+	regions, regionsErr := schema.Region_get_all_from_db(wdb)
+	if regionsErr != nil {
+		return fmt.Errorf("%w: regions: %v", schema.ErrWorldDataUnavailable, regionsErr)
+	}
+	return CheckLocaleEntryRequirements(userData, regions, localeSymbol)
+}
+
+// Check whether any of the user's golems is at localeSymbol
+func doesUserHaveGolemAtLocale(userData schema.User, localeSymbol string) bool {
+	for _, golem := range userData.Golems {
+		if strings.EqualFold(golem.LocationSymbol, localeSymbol) {
+			return true
+		}
+	}
+	return false
+}
+
+// Perform the ritual repetitions times in a row, checking the total mana cost up front
+// A failed repetition returns its error, the caller must then discard userData rather than save it so the batch is all or nothing
+func PerformRitualRepeatedly(userData *schema.User, ritual schema.Ritual, target schema.RitualTarget, repetitions int, wdb rdb.Database, timestamp int64) (schema.RitualBatchResult, error) {
+	localeSymbol := target.LocaleSymbol
+	batch := schema.RitualBatchResult{
+		RitualSymbol: ritual.Symbol,
+		LocaleSymbol: localeSymbol,
+		Repetitions: repetitions,
+		ManaSpent: 0,
+		Results: make([]schema.RitualResult, 0, repetitions),
+	}
+	if repetitions < 1 || repetitions > Max_Ritual_Repetitions {
+		return batch, fmt.Errorf("%w: repetitions must be from 1 to %d", schema.ErrRequirementsNotMet, Max_Ritual_Repetitions)
+	}
+	if repetitions > 1 && !schema.Repeatable_Ritual_Effects[ritual.Effect.Type] {
+		return batch, fmt.Errorf("%w: %s cannot be repeated", schema.ErrRequirementsNotMet, ritual.Symbol)
+	}
+	totalCost := getRitualTotalCost(*userData, ritual, target.GolemIndex, repetitions)
+	circleMana := userData.RitualCircles[localeSymbol].Mana
+	if circleMana + userData.Mana < totalCost {
+		return batch, fmt.Errorf("%w: have %v in the circle at %s and %v of your own but %d x %s costs %v", schema.ErrInsufficientMana, circleMana, localeSymbol, userData.Mana, repetitions, ritual.Symbol, totalCost)
+	}
+	for i := 0; i < repetitions; i++ {
+		result, err := PerformRitual(userData, ritual, target, wdb, timestamp)
+		if err != nil {
+			return batch, fmt.Errorf("repetition %d of %d failed: %w", i+1, repetitions, err)
+		}
+		batch.ManaSpent += result.ManaSpent
+		batch.Results = append(batch.Results, result)
+	}
+	return batch, nil
+}
+
+// Get the mana repetitions of the ritual will cost in total, golem rituals are priced off the golem
+func getRitualTotalCost(userData schema.User, ritual schema.Ritual, golemIndex int, repetitions int) float64 {
+	switch ritual.Effect.Type {
+	case "upgrade_golem":
+		// Each level costs more than the last
+		golem := userData.Golems[golemIndex]
+		total := 0.0
+		for i := 0; i < repetitions; i++ {
+			total += GetUpgradeCost(golem, ritual)
+			golem.Level++
+		}
+		return total
+	case "recharge_golem":
+		return GetRechargeCost(userData.Golems[golemIndex], ritual)
+	case "repair_golem":
+		golem := userData.Golems[golemIndex]
+		return math.Ceil(math.Max(0, float64(golem.MaxHealth - golem.Health)) * ritual.ManaCost)
+	case "teleport":
+		// Priced once its path is planned, every path is at least one hop at the flat cost
+		return ritual.ManaCost
+	}
+	return ritual.ManaCost * float64(repetitions)
+}
+
+// Get the total amount buffs add to stat over the seconds from start to end, counting each only while active
+func getBuffTotal(userData schema.User, stat string, start int64, end int64) float64 {
+	total := 0.0
+	for _, buff := range userData.Buffs {
+		if buff.Stat != stat {
+			continue
+		}
+		activeSeconds := math.Min(float64(end), float64(buff.ExpiresAt)) - float64(start)
+		if activeSeconds > 0 {
+			total += buff.Amount * activeSeconds
+		}
+	}
+	return total
+}
+
+// Remove buffs that have expired by now, return the updated userData
+func PruneExpiredBuffs(userData schema.User, now int64) (schema.User) {
+	if len(userData.Buffs) < 1 {
+		return userData
+	}
+	buffs := make([]schema.Buff, 0, len(userData.Buffs))
+	for _, buff := range userData.Buffs {
+		if buff.ExpiresAt > now {
+			buffs = append(buffs, buff)
+		}
+	}
+	userData.Buffs = buffs
+	return userData
+}
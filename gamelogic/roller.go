@@ -0,0 +1,41 @@
+// Package gamelogic provides functions for game logic
+package gamelogic
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// Deterministic source of random rolls, the same seed always gives the same sequence
+// Seeding from the event being rolled for means replaying a period of time always has the same outcome
+type Roller struct {
+	state uint64
+}
+
+// Create a roller seeded from seedParts, e.g. username, golem symbol, roll kind and event timestamp
+func NewRoller(seedParts ...interface{}) (*Roller) {
+	hash := fnv.New64a()
+	for _, part := range seedParts {
+		fmt.Fprintf(hash, "%v|", part)
+	}
+	return &Roller{state: hash.Sum64()}
+}
+
+// Next value of the sequence, splitmix64
+func (r *Roller) next() uint64 {
+	r.state += 0x9e3779b97f4a7c15
+	z := r.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Roll a float in [0, 1)
+func (r *Roller) Float64() float64 {
+	return float64(r.next() >> 11) / (1 << 53)
+}
+
+// Roll an int in [0, n), n must be positive
+func (r *Roller) Intn(n int) int {
+	return int(r.next() % uint64(n))
+}
@@ -0,0 +1,133 @@
+// Package gamelogic provides functions for game logic
+package gamelogic
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/brct-james/guild-golems/schema"
+)
+
+// Ways a route plan can be optimized, mapped to the weight of a single route
+var RoutePlanWeights = map[string]func(schema.Route) float64 {
+	"time": func(route schema.Route) float64 { return float64(route.TravelTime) },
+	"cost": func(route schema.Route) float64 { return float64(route.Cost) },
+	"danger": func(route schema.Route) float64 { return float64(route.DangerLevel) },
+	"hops": func(route schema.Route) float64 { return 1 },
+}
+
+// Find the chain of routes from origin to destination with the lowest total weight for optimize (time, cost, danger or hops)
+// Ties are broken by travel time, so e.g. the safest path found is also the quickest of the safest
+func PlanRoute(routes map[string]schema.Route, originSymbol string, destinationSymbol string, optimize string) (schema.RoutePlan, error) {
+	weight, ok := RoutePlanWeights[strings.ToLower(optimize)]
+	if !ok {
+		return schema.RoutePlan{}, fmt.Errorf("cannot optimize for %s, must be one of time, cost, danger or hops", optimize)
+	}
+	// Build adjacency lists keyed by origin, sorted so equal paths are always chosen the same way
+	edges := make(map[string][]schema.Route)
+	for _, route := range routes {
+		routeOrigin, _, parseErr := schema.ParseRouteSymbol(route.Symbol)
+		if parseErr != nil {
+			return schema.RoutePlan{}, parseErr
+		}
+		edges[routeOrigin] = append(edges[routeOrigin], route)
+	}
+	for origin := range edges {
+		sort.Slice(edges[origin], func(i, j int) bool { return edges[origin][i].Symbol < edges[origin][j].Symbol })
+	}
+
+	// Dijkstra, the world is small enough that a linear scan for the next locale is fine
+	type distance struct {
+		weight float64
+		travelTime int
+	}
+	distances := map[string]distance{originSymbol: {0, 0}}
+	previous := make(map[string]schema.Route)
+	visited := make(map[string]bool)
+	for {
+		current := ""
+		best := distance{math.Inf(1), math.MaxInt32}
+		for symbol, dist := range distances {
+			if visited[symbol] {
+				continue
+			}
+			if dist.weight < best.weight || (dist.weight == best.weight && dist.travelTime < best.travelTime) || (dist.weight == best.weight && dist.travelTime == best.travelTime && symbol < current) {
+				current, best = symbol, dist
+			}
+		}
+		if current == "" || current == destinationSymbol {
+			break
+		}
+		visited[current] = true
+		for _, route := range edges[current] {
+			_, next, _ := schema.ParseRouteSymbol(route.Symbol)
+			candidate := distance{best.weight + weight(route), best.travelTime + route.TravelTime}
+			known, seen := distances[next]
+			if !seen || candidate.weight < known.weight || (candidate.weight == known.weight && candidate.travelTime < known.travelTime) {
+				distances[next] = candidate
+				previous[next] = route
+			}
+		}
+	}
+	if _, reached := distances[destinationSymbol]; !reached {
+		return schema.RoutePlan{}, fmt.Errorf("%w from %s to %s", schema.ErrNoPathFound, originSymbol, destinationSymbol)
+	}
+
+	// Walk back from the destination to recover the path
+	path := make([]schema.Route, 0)
+	for symbol := destinationSymbol; symbol != originSymbol; {
+		route := previous[symbol]
+		path = append([]schema.Route{route}, path...)
+		symbol, _, _ = schema.ParseRouteSymbol(route.Symbol)
+	}
+	plan := NewRoutePlan(originSymbol, destinationSymbol, path)
+	plan.Optimize = strings.ToLower(optimize)
+	return plan, nil
+}
+
+// Summarize path as a RoutePlan, totalling time, costs and danger across every hop
+func NewRoutePlan(originSymbol string, destinationSymbol string, path []schema.Route) schema.RoutePlan {
+	plan := schema.RoutePlan{
+		OriginSymbol: originSymbol,
+		DestinationSymbol: destinationSymbol,
+		RouteSymbols: make([]string, 0, len(path)),
+	}
+	for _, route := range path {
+		plan.RouteSymbols = append(plan.RouteSymbols, route.Symbol)
+		plan.TotalTravelTime += route.TravelTime
+		plan.TotalDanger += route.DangerLevel
+		if strings.EqualFold(route.CostType, "mana") {
+			plan.TotalManaCost += route.Cost
+		} else {
+			plan.TotalCoinCost += route.Cost
+		}
+	}
+	return plan
+}
+
+// Look up each route symbol, checking every hop starts where the previous one ended, beginning at originSymbol
+func ResolvePath(routes map[string]schema.Route, originSymbol string, routeSymbols []string) ([]schema.Route, error) {
+	if len(routeSymbols) < 1 {
+		return nil, fmt.Errorf("%w: path must contain at least one route", schema.ErrRouteUnavailable)
+	}
+	path := make([]schema.Route, 0, len(routeSymbols))
+	position := originSymbol
+	for _, routeSymbol := range routeSymbols {
+		route, ok := routes[routeSymbol]
+		if !ok {
+			return nil, fmt.Errorf("%w: no such route %s", schema.ErrRouteUnavailable, routeSymbol)
+		}
+		routeOrigin, routeDestination, parseErr := schema.ParseRouteSymbol(route.Symbol)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		if !strings.EqualFold(routeOrigin, position) {
+			return nil, fmt.Errorf("%w: %s does not start at %s", schema.ErrRouteUnavailable, routeSymbol, position)
+		}
+		path = append(path, route)
+		position = routeDestination
+	}
+	return path, nil
+}
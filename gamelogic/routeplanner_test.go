@@ -0,0 +1,102 @@
+package gamelogic
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/brct-james/guild-golems/schema"
+)
+
+// Get a route from symbol with the given travel time, coin cost and danger level
+func newTestRoute(symbol string, travelTime int, cost int, danger int) (schema.Route) {
+	route := schema.Route{TravelTime: travelTime, Cost: cost, DangerLevel: danger, CostType: "coin"}
+	route.Symbol = symbol
+	return route
+}
+
+// Three ways from A to D: a fast but costly and dangerous direct route, a cheap road via B and a safe path via C
+func newTestRoutes() (map[string]schema.Route) {
+	routes := make(map[string]schema.Route)
+	for _, route := range []schema.Route{
+		newTestRoute("A|D|FAST", 10, 100, 9),
+		newTestRoute("A|B|ROAD", 20, 1, 1),
+		newTestRoute("B|D|ROAD", 20, 1, 1),
+		newTestRoute("A|C|SAFE", 30, 5, 0),
+		newTestRoute("C|D|SAFE", 30, 5, 0),
+		newTestRoute("E|A|ROAD", 5, 1, 1),
+	} {
+		routes[route.Symbol] = route
+	}
+	return routes
+}
+
+func TestPlanRouteOptimizesForEachMode(t *testing.T) {
+	tests := []struct {
+		optimize string
+		expectedRoutes []string
+		expectedTravelTime int
+		expectedCoinCost int
+		expectedDanger int
+	}{
+		{"time", []string{"A|D|FAST"}, 10, 100, 9},
+		{"cost", []string{"A|B|ROAD", "B|D|ROAD"}, 40, 2, 2},
+		{"danger", []string{"A|C|SAFE", "C|D|SAFE"}, 60, 10, 0},
+		{"hops", []string{"A|D|FAST"}, 10, 100, 9},
+		{"COST", []string{"A|B|ROAD", "B|D|ROAD"}, 40, 2, 2},
+	}
+	for _, test := range tests {
+		plan, err := PlanRoute(newTestRoutes(), "A", "D", test.optimize)
+		if err != nil {
+			t.Fatalf("optimizing for %s: expected a plan, got error %v", test.optimize, err)
+		}
+		if strings.Join(plan.RouteSymbols, ",") != strings.Join(test.expectedRoutes, ",") {
+			t.Fatalf("optimizing for %s: expected routes %v, got %v", test.optimize, test.expectedRoutes, plan.RouteSymbols)
+		}
+		if plan.TotalTravelTime != test.expectedTravelTime || plan.TotalCoinCost != test.expectedCoinCost || plan.TotalDanger != test.expectedDanger {
+			t.Fatalf("optimizing for %s: expected time %d, coin cost %d and danger %d, got %d, %d and %d", test.optimize, test.expectedTravelTime, test.expectedCoinCost, test.expectedDanger, plan.TotalTravelTime, plan.TotalCoinCost, plan.TotalDanger)
+		}
+		if plan.Optimize != strings.ToLower(test.optimize) {
+			t.Fatalf("optimizing for %s: expected plan optimize %s, got %s", test.optimize, strings.ToLower(test.optimize), plan.Optimize)
+		}
+	}
+}
+
+func TestPlanRouteBreaksTiesByTravelTime(t *testing.T) {
+	routes := newTestRoutes()
+	// Now as safe as the path via C but slower, so the path via C must still win
+	slowSafe := newTestRoute("A|D|SLOW", 90, 5, 0)
+	routes[slowSafe.Symbol] = slowSafe
+	plan, err := PlanRoute(routes, "A", "D", "danger")
+	if err != nil {
+		t.Fatalf("expected a plan, got error %v", err)
+	}
+	if strings.Join(plan.RouteSymbols, ",") != "A|C|SAFE,C|D|SAFE" {
+		t.Fatalf("expected the quicker of the safest paths via C, got %v", plan.RouteSymbols)
+	}
+}
+
+func TestPlanRouteReportsNoPathFound(t *testing.T) {
+	tests := []struct {
+		name string
+		origin string
+		destination string
+	}{
+		{"routes only run one way", "D", "A"},
+		{"nothing leads to the origin's predecessor", "A", "E"},
+		{"unknown destination", "A", "Z"},
+	}
+	for _, test := range tests {
+		_, err := PlanRoute(newTestRoutes(), test.origin, test.destination, "time")
+		if !errors.Is(err, schema.ErrNoPathFound) {
+			t.Fatalf("%s: expected ErrNoPathFound from %s to %s, got %v", test.name, test.origin, test.destination, err)
+		}
+	}
+}
+
+func TestPlanRouteRejectsUnknownOptimize(t *testing.T) {
+	_, err := PlanRoute(newTestRoutes(), "A", "D", "scenery")
+	if err == nil || errors.Is(err, schema.ErrNoPathFound) {
+		t.Fatalf("expected an invalid optimize error, got %v", err)
+	}
+}
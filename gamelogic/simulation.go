@@ -0,0 +1,384 @@
+// Package gamelogic provides functions for game logic
+package gamelogic
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/brct-james/guild-golems/log"
+	"github.com/brct-james/guild-golems/rdb"
+	"github.com/brct-james/guild-golems/schema"
+)
+
+// A time-based event in a user's simulation, e.g. a golem arriving at the end of a hop
+type simulationEvent struct {
+	Time int64
+	Kind string
+	GolemIndex int
+}
+
+// State for replaying one user's events from Start up to End
+type simulation struct {
+	UserData *schema.User
+	Wdb rdb.Database
+	Start int64
+	End int64
+	lastEventTime int64
+	// World data is only fetched the first time an event needs it
+	worldLoaded bool
+	worldErr error
+	nodes map[string]schema.ResourceNode
+	resources map[string]schema.Resource
+	recipes map[string]schema.Recipe
+	blueprints map[string]schema.Blueprint
+	routes map[string]schema.Route
+	// Harvests drawn from finite nodes ahead of time but not yet used, keyed by golem index
+	reservedHarvests map[int]int64
+	reservedNodes map[int]schema.ResourceNode
+	// Golems whose node could not grant every harvest they asked for, keyed to when it will have replenished enough to ask again
+	retryDrawAt map[int]int64
+	// Golems whose next event could not be processed, they are retried on the next update
+	stalled map[int]bool
+}
+
+// World data a simulation reads, keyed by symbol
+type SimulationWorld struct {
+	Nodes map[string]schema.ResourceNode
+	Resources map[string]schema.Resource
+	Recipes map[string]schema.Recipe
+	Blueprints map[string]schema.Blueprint
+	Routes map[string]schema.Route
+}
+
+// Loads the world data for simulations, replace to simulate without a wdb
+var LoadSimulationWorld = loadSimulationWorldFromDB
+
+// Get every world data map a simulation reads from the wdb
+func loadSimulationWorldFromDB(wdb rdb.Database) (SimulationWorld, error) {
+	var world SimulationWorld
+	var err error
+	world.Nodes, err = schema.ResourceNode_get_all_from_db(wdb)
+	if err == nil {
+		world.Resources, err = schema.Resource_get_all_from_db(wdb)
+	}
+	if err == nil {
+		world.Recipes, err = schema.Recipe_get_all_from_db(wdb)
+	}
+	if err == nil {
+		world.Blueprints, err = schema.Blueprint_get_all_from_db(wdb)
+	}
+	if err == nil {
+		world.Routes, err = schema.Route_get_all_from_db(wdb)
+	}
+	return world, err
+}
+
+// Replay every time-based event of the user in chronological order from their last update up to the clock's now, return the updated userData
+// Mana, harvest ticks, arrivals, completions and queued orders interleave exactly as if they had been calculated live
+func SimulateUserUpdates(userData schema.User, wdb rdb.Database, clock Clock) (schema.User) {
+	log.Debug.Println(log.Cyan("-- Begin SimulateUserUpdates --"))
+	sim := simulation{
+		UserData: &userData,
+		Wdb: wdb,
+		Start: userData.LastManaTick,
+		End: clock.Now().Unix(),
+		reservedHarvests: make(map[int]int64),
+		reservedNodes: make(map[int]schema.ResourceNode),
+		retryDrawAt: make(map[int]int64),
+		stalled: make(map[int]bool),
+	}
+	processed := 0
+	for {
+		event, found := sim.nextEvent()
+		if !found || event.Time > sim.End {
+			break
+		}
+		if processed >= Max_Simulation_Events {
+			// Stop at the last replayed event, advancing further would skip past the events left for the next update
+			log.Error.Printf("Replayed %d events for user %s, the rest are replayed on the next update", processed, userData.Username)
+			sim.End = sim.lastEventTime
+			break
+		}
+		sim.advanceTo(event.Time)
+		sim.processEvent(event)
+		sim.lastEventTime = event.Time
+		processed++
+	}
+	sim.advanceTo(sim.End)
+	sim.finish()
+	log.Debug.Println(log.Cyan("-- End SimulateUserUpdates --"))
+	return userData
+}
+
+// Fetch the world data events depend on, only the first call reaches the wdb
+func (sim *simulation) loadWorld() error {
+	if sim.worldLoaded {
+		return sim.worldErr
+	}
+	sim.worldLoaded = true
+	var world SimulationWorld
+	world, sim.worldErr = LoadSimulationWorld(sim.Wdb)
+	if sim.worldErr != nil {
+		log.Error.Printf("Could not get world data from DB for simulation! Err: %v", sim.worldErr)
+		return sim.worldErr
+	}
+	sim.nodes = world.Nodes
+	sim.resources = world.Resources
+	sim.recipes = world.Recipes
+	sim.blueprints = world.Blueprints
+	sim.routes = world.Routes
+	return nil
+}
+
+// Find the earliest pending event, ties go to the lowest golem index so replays are always in the same order
+func (sim *simulation) nextEvent() (simulationEvent, bool) {
+	var next simulationEvent
+	found := false
+	consider := func(event simulationEvent) {
+		if !found || event.Time < next.Time {
+			next = event
+			found = true
+		}
+	}
+	anyBuilding := false
+	for i, golem := range sim.UserData.Golems {
+		if sim.stalled[i] {
+			continue
+		}
+		switch {
+		case strings.EqualFold(golem.Status, "idle") && len(golem.Orders) > 0:
+			// Queued orders wait for the golem to regenerate enough energy, those left over from before the last update start from then
+			readyTime, ready := GetEnergyReadyTime(golem)
+			if !ready {
+				continue
+			}
+			startTime := golem.IdleSince
+			if startTime < readyTime {
+				startTime = readyTime
+			}
+			if startTime < sim.Start {
+				startTime = sim.Start
+			}
+			consider(simulationEvent{Time: startTime, Kind: "orders", GolemIndex: i})
+		case strings.EqualFold(golem.Status, "harvesting"):
+			if sim.loadWorld() != nil {
+				sim.stalled[i] = true
+				continue
+			}
+			node, ok := sim.nodes[golem.HarvestInfo.NodeSymbol]
+			if !ok || node.HarvestTime <= 0 {
+				log.Error.Printf("Golem %s is harvesting unknown or invalid resource node %s", golem.Symbol, golem.HarvestInfo.NodeSymbol)
+				sim.stalled[i] = true
+				continue
+			}
+			consider(simulationEvent{Time: golem.HarvestInfo.LastHarvestTick + GetGolemHarvestTime(golem, node), Kind: "harvest", GolemIndex: i})
+		case strings.EqualFold(golem.Status, "traveling") || strings.EqualFold(golem.Status, "delivering"):
+			consider(simulationEvent{Time: golem.TravelInfo.ArrivalTime, Kind: "arrival", GolemIndex: i})
+		case strings.EqualFold(golem.Status, "crafting"):
+			consider(simulationEvent{Time: golem.CraftInfo.CompletionTime, Kind: "craft", GolemIndex: i})
+		case strings.EqualFold(golem.Status, "building"):
+			anyBuilding = true
+		}
+		exhaustionTime, exhausts := GetEnergyExhaustionTime(golem)
+		if exhausts {
+			consider(simulationEvent{Time: exhaustionTime, Kind: "exhausted", GolemIndex: i})
+		}
+	}
+	if sim.UserData.ActiveResearch.ResearchSymbol != "" {
+		consider(simulationEvent{Time: sim.UserData.ActiveResearch.CompletionTime, Kind: "research", GolemIndex: -1})
+	}
+	if anyBuilding && sim.loadWorld() == nil {
+		for _, sites := range sim.UserData.Buildings {
+			for blueprintSymbol, building := range sites {
+				blueprint, ok := sim.blueprints[blueprintSymbol]
+				if !ok {
+					continue
+				}
+				completionTime, ok := EstimateBuildingCompletion(sim.UserData, building, blueprint)
+				if ok {
+					consider(simulationEvent{Time: completionTime, Kind: "building", GolemIndex: -1})
+				}
+			}
+		}
+	}
+	return next, found
+}
+
+// Bring continuous processes, mana regen and buffs, construction and energy, up to now
+// Energy is last as golems running out stop working, which the others must count up to now
+func (sim *simulation) advanceTo(now int64) {
+	*sim.UserData = CalculateManaRegen(*sim.UserData, now)
+	*sim.UserData = PruneExpiredBuffs(*sim.UserData, now)
+	if len(schema.FilterGolemListByStatus(sim.UserData.Golems, "building")) < 1 || sim.loadWorld() == nil {
+		// Otherwise leave construction where it is rather than lose progress to ticks moving forward
+		*sim.UserData = CalculateBuildingProgress(*sim.UserData, sim.blueprints, now)
+	}
+	*sim.UserData = CalculateGolemEnergy(*sim.UserData, now)
+	for golemIndex := range sim.reservedNodes {
+		if !strings.EqualFold(sim.UserData.Golems[golemIndex].Status, "harvesting") {
+			sim.releaseHarvests(golemIndex)
+		}
+	}
+}
+
+// Apply event, continuous processes must already be advanced to its time
+func (sim *simulation) processEvent(event simulationEvent) {
+	switch event.Kind {
+	case "orders":
+		sim.startQueuedOrders(event.GolemIndex, event.Time)
+	case "harvest":
+		sim.harvestTick(event.GolemIndex, event.Time)
+	case "arrival":
+		sim.arrive(event.GolemIndex, event.Time)
+	case "craft":
+		sim.completeCraft(event.GolemIndex)
+	case "building":
+		// Advancing construction to the event time already completed it
+	case "exhausted":
+		// Advancing energy to the event time already stopped the golem
+	case "research":
+		CompleteResearch(sim.UserData)
+	}
+}
+
+// Start the golem's next queued orders from now
+func (sim *simulation) startQueuedOrders(golemIndex int, now int64) {
+	golem := &sim.UserData.Golems[golemIndex]
+	advanceGolemOrders(sim.UserData, golemIndex, sim.Wdb, time.Unix(now, 0))
+	if strings.EqualFold(golem.Status, "idle") && len(golem.Orders) > 0 {
+		// Only instant orders repeating, which would never let time pass
+		schema.AddGolemEvent(golem, now, "order_failed", "Orders repeat without taking any time, orders cleared")
+		ClearGolemOrders(golem)
+	}
+}
+
+// Harvest once from the golem's node at now, the golem stops if its cargo fills up
+func (sim *simulation) harvestTick(golemIndex int, now int64) {
+	golem := &sim.UserData.Golems[golemIndex]
+	node := sim.nodes[golem.HarvestInfo.NodeSymbol]
+	drawn, drawErr := sim.drawHarvest(golemIndex, node, now)
+	if drawErr != nil {
+		log.Error.Printf("Could not draw from resource node %s for golem %s! Err: %v", node.Symbol, golem.Symbol, drawErr)
+		sim.stalled[golemIndex] = true
+		return
+	}
+	golem.HarvestInfo.LastHarvestTick = now
+	if !drawn {
+		// The node is exhausted, the tick passes without yield until it replenishes
+		return
+	}
+	GrantGolemExperience(golem, Experience_Per_Harvest, now)
+	roller := NewRoller(sim.UserData.Username, golem.Symbol, "harvest", now)
+	isFull := storeHarvestYield(sim.UserData, golemIndex, node, RollHarvestYield(node, 1, roller), sim.resources)
+	if isFull {
+		log.Debug.Printf("Golem %s cargo full, setting to idle", golem.Symbol)
+		golem.Status = "idle"
+		golem.IdleSince = now
+		sim.releaseHarvests(golemIndex)
+	}
+}
+
+// Take one harvest at now from the golem's reservation, returning false if the node had none left to give
+// Reserves every tick the golem could harvest up to End at once, rather than drawing on the shared pool tick by tick
+// A golem that levels up harvests faster than it reserved for, so it reserves again when it runs out
+// A node that runs short is asked again once it should have replenished a harvest, so the golem resumes as it refills
+func (sim *simulation) drawHarvest(golemIndex int, node schema.ResourceNode, now int64) (bool, error) {
+	reserved := sim.reservedHarvests[golemIndex]
+	retryAt, drewShort := sim.retryDrawAt[golemIndex]
+	if reserved < 1 && (!drewShort || now >= retryAt) {
+		golem := sim.UserData.Golems[golemIndex]
+		requested := (sim.End - golem.HarvestInfo.LastHarvestTick) / GetGolemHarvestTime(golem, node)
+		// Replenish only up to now, what the node regains later is drawn on by later retries
+		granted, drawErr := TryDrawFromNode(sim.Wdb, node, requested, now)
+		if drawErr != nil {
+			return false, drawErr
+		}
+		delete(sim.retryDrawAt, golemIndex)
+		if granted < requested {
+			sim.retryDrawAt[golemIndex] = getNodeRetryTime(node, now)
+		}
+		reserved = granted
+		sim.reservedNodes[golemIndex] = node
+	}
+	if reserved < 1 {
+		sim.reservedHarvests[golemIndex] = 0
+		return false, nil
+	}
+	sim.reservedHarvests[golemIndex] = reserved - 1
+	return true, nil
+}
+
+// Get when a node that ran short at now will have replenished at least one harvest, never for nodes that do not replenish
+func getNodeRetryTime(node schema.ResourceNode, now int64) int64 {
+	if node.ReplenishRate <= 0 {
+		return math.MaxInt64
+	}
+	return now + int64(math.Max(1, math.Ceil(1/node.ReplenishRate)))
+}
+
+// Give back harvests the golem reserved but will not use
+func (sim *simulation) releaseHarvests(golemIndex int) {
+	node, ok := sim.reservedNodes[golemIndex]
+	if !ok {
+		return
+	}
+	reserved := sim.reservedHarvests[golemIndex]
+	delete(sim.reservedHarvests, golemIndex)
+	delete(sim.reservedNodes, golemIndex)
+	delete(sim.retryDrawAt, golemIndex)
+	returnErr := ReturnToNode(sim.Wdb, node, reserved, sim.End)
+	if returnErr != nil {
+		log.Error.Printf("Could not return %d unused harvests to resource node %s! Err: %v", reserved, node.Symbol, returnErr)
+	}
+}
+
+// Roll the danger of the golem's current hop and complete it if the golem is not delayed
+func (sim *simulation) arrive(golemIndex int, now int64) {
+	golem := &sim.UserData.Golems[golemIndex]
+	if golem.TravelInfo.RouteDanger > 0 {
+		// Roll danger once on arrival, a delay pushes arrival back to a later event
+		roller := NewRoller(sim.UserData.Username, golem.Symbol, "danger", golem.TravelInfo.ArrivalTime)
+		ApplyDangerOutcome(golem, RollDangerOutcome(*golem, roller), now)
+		if golem.TravelInfo.ArrivalTime > now {
+			return
+		}
+	}
+	if len(golem.TravelInfo.RemainingRoutes) > 0 && sim.loadWorld() != nil {
+		// Leave the golem in transit, it will arrive on a later update
+		sim.stalled[golemIndex] = true
+		return
+	}
+	CompleteTravelHop(sim.UserData, golemIndex, sim.routes)
+}
+
+// Finish the golem's craft, depositing its outputs
+func (sim *simulation) completeCraft(golemIndex int) {
+	golem := &sim.UserData.Golems[golemIndex]
+	if sim.loadWorld() != nil {
+		// Leave golem crafting so outputs are not lost if the wdb is temporarily unavailable
+		sim.stalled[golemIndex] = true
+		return
+	}
+	recipe, ok := sim.recipes[golem.CraftInfo.RecipeSymbol]
+	if !ok {
+		log.Error.Printf("Golem %s is crafting unknown recipe %s", golem.Symbol, golem.CraftInfo.RecipeSymbol)
+		sim.stalled[golemIndex] = true
+		return
+	}
+	CompleteCraft(sim.UserData, golemIndex, recipe, sim.resources)
+}
+
+// Update the progress of golems still in transit and give back any unused harvest reservations
+func (sim *simulation) finish() {
+	for i := range sim.UserData.Golems {
+		golem := &sim.UserData.Golems[i]
+		if strings.EqualFold(golem.Status, "traveling") || strings.EqualFold(golem.Status, "delivering") {
+			golem.TravelInfo.Progress = CalculateTravelProgress(golem.TravelInfo, sim.End)
+		}
+	}
+	for golemIndex := range sim.reservedNodes {
+		sim.releaseHarvests(golemIndex)
+	}
+}
+
@@ -0,0 +1,166 @@
+package gamelogic
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/brct-james/guild-golems/rdb"
+	"github.com/brct-james/guild-golems/schema"
+)
+
+// Start of every test simulation
+var testStart = time.Unix(1000000, 0)
+
+// Replace the simulation's world loader with world for the duration of the test
+func useTestWorld(t *testing.T, world SimulationWorld) {
+	previous := LoadSimulationWorld
+	LoadSimulationWorld = func(wdb rdb.Database) (SimulationWorld, error) {
+		return world, nil
+	}
+	t.Cleanup(func() {
+		LoadSimulationWorld = previous
+	})
+}
+
+// Get a user last updated at testStart with golem as their only golem
+func newTestUser(golem schema.Golem) (schema.User) {
+	userData := schema.NewUser("test-token", "tester")
+	userData.LastManaTick = testStart.Unix()
+	golem.LastEnergyTick = testStart.Unix()
+	userData.Golems = append(userData.Golems, golem)
+	return userData
+}
+
+// A world with one infinite node dropping a unit of ore every 10 seconds and a two hop path A-G to A-SWF to A-N
+func newTestWorld() (SimulationWorld) {
+	ore := schema.Resource{CapacityPerUnit: 1}
+	ore.Symbol = "ore"
+	node := schema.ResourceNode{HarvestTime: 10, DropTables: []schema.DropTable{{ResourceSymbol: "ore", Rarity: 1, HarvestAmount: 1}}}
+	node.Symbol = "A-G-ORE"
+	secondHop := schema.Route{TravelTime: 60}
+	secondHop.Symbol = "A-SWF|A-N|WALK"
+	return SimulationWorld{
+		Nodes: map[string]schema.ResourceNode{node.Symbol: node},
+		Resources: map[string]schema.Resource{ore.Symbol: ore},
+		Recipes: map[string]schema.Recipe{},
+		Blueprints: map[string]schema.Blueprint{},
+		Routes: map[string]schema.Route{secondHop.Symbol: secondHop},
+	}
+}
+
+// Get the first golem of archetype a user would summon, symbol prefixed like real golems
+func newTestGolem(archetype string, status string, capacity float64) (schema.Golem) {
+	symbol := fmt.Sprintf("%s-0", schema.GolemArchetypes[archetype].Abbreviation)
+	return schema.NewGolem(symbol, archetype, status, capacity)
+}
+
+// Get a harvester harvesting the test world's node since testStart
+func newTestHarvester(capacity float64) (schema.Golem) {
+	golem := newTestGolem("harvester", "harvesting", capacity)
+	golem.HarvestInfo.NodeSymbol = "A-G-ORE"
+	golem.HarvestInfo.LastHarvestTick = testStart.Unix()
+	return golem
+}
+
+func TestSimulateTripFollowsEveryHop(t *testing.T) {
+	useTestWorld(t, newTestWorld())
+	clock := &ManualClock{Time: testStart}
+	golem := newTestGolem("courier", "traveling", 10)
+	firstHop := schema.Route{TravelTime: 30}
+	firstHop.Symbol = "A-G|A-SWF|WALK"
+	startTravelHop(&golem, firstHop, testStart)
+	golem.TravelInfo.RemainingRoutes = []string{"A-SWF|A-N|WALK"}
+	userData := newTestUser(golem)
+
+	clock.Advance(45 * time.Second)
+	userData = SimulateUserUpdates(userData, rdb.Database{}, clock)
+	courier := userData.Golems[0]
+	if courier.Status != "traveling" || courier.TravelInfo.DestinationSymbol != "A-N" || courier.TravelInfo.DepartureTime != testStart.Unix()+30 {
+		t.Fatalf("expected second hop to A-N departing at +30s, got status %s to %s departing at %d", courier.Status, courier.TravelInfo.DestinationSymbol, courier.TravelInfo.DepartureTime)
+	}
+
+	clock.Advance(time.Hour)
+	userData = SimulateUserUpdates(userData, rdb.Database{}, clock)
+	courier = userData.Golems[0]
+	if courier.Status != "idle" || courier.LocationSymbol != "A-N" || courier.IdleSince != testStart.Unix()+90 {
+		t.Fatalf("expected idle at A-N since +90s, got %s at %s since %d", courier.Status, courier.LocationSymbol, courier.IdleSince)
+	}
+	if courier.Experience != 2*Experience_Per_Trip {
+		t.Fatalf("expected experience for two hops, got %d", courier.Experience)
+	}
+}
+
+func TestSimulateHarvestStopsWhenCargoIsFull(t *testing.T) {
+	useTestWorld(t, newTestWorld())
+	clock := &ManualClock{Time: testStart}
+	userData := newTestUser(newTestHarvester(5))
+
+	clock.Advance(35 * time.Second)
+	userData = SimulateUserUpdates(userData, rdb.Database{}, clock)
+	if quantity := userData.Golems[0].Cargo["ore"].Quantity; quantity != 3 {
+		t.Fatalf("expected 3 ore after 35s, got %d", quantity)
+	}
+
+	clock.Advance(time.Hour)
+	userData = SimulateUserUpdates(userData, rdb.Database{}, clock)
+	harvester := userData.Golems[0]
+	if harvester.Cargo["ore"].Quantity != 5 || harvester.Status != "idle" || harvester.IdleSince != testStart.Unix()+60 {
+		t.Fatalf("expected 5 ore and idle since +60s, got %d ore and %s since %d", harvester.Cargo["ore"].Quantity, harvester.Status, harvester.IdleSince)
+	}
+}
+
+func TestSimulateEnergyExhaustionStopsHarvesting(t *testing.T) {
+	useTestWorld(t, newTestWorld())
+	clock := &ManualClock{Time: testStart}
+	golem := newTestHarvester(1000)
+	// Harvesting drains 0.01 per second, so 1 energy lasts about 100 seconds
+	golem.Energy = 1
+	userData := newTestUser(golem)
+	exhaustionTime, exhausts := GetEnergyExhaustionTime(userData.Golems[0])
+	if !exhausts {
+		t.Fatalf("expected a harvesting golem to run out of energy")
+	}
+
+	clock.Advance(time.Hour)
+	userData = SimulateUserUpdates(userData, rdb.Database{}, clock)
+	harvester := userData.Golems[0]
+	if harvester.Status != "idle" || harvester.IdleSince != exhaustionTime {
+		t.Fatalf("expected idle since %d, got %s since %d", exhaustionTime, harvester.Status, harvester.IdleSince)
+	}
+	if expected := int((exhaustionTime - testStart.Unix()) / 10); harvester.Cargo["ore"].Quantity != expected {
+		t.Fatalf("expected %d ore harvested before exhaustion, got %d", expected, harvester.Cargo["ore"].Quantity)
+	}
+	// Idle golems regenerate from the moment they stopped
+	expectedEnergy := float64(clock.Now().Unix()-exhaustionTime) * schema.Golem_Energy_Regen
+	if harvester.Energy < expectedEnergy-1e-9 || harvester.Energy > expectedEnergy+1e-9 {
+		t.Fatalf("expected %v energy after resting, got %v", expectedEnergy, harvester.Energy)
+	}
+}
+
+func TestSimulateStopsAtLastEventWhenCapped(t *testing.T) {
+	useTestWorld(t, newTestWorld())
+	previousMax := Max_Simulation_Events
+	Max_Simulation_Events = 2
+	t.Cleanup(func() {
+		Max_Simulation_Events = previousMax
+	})
+	clock := &ManualClock{Time: testStart}
+	userData := newTestUser(newTestHarvester(1000))
+	uncapped, _ := schema.CopyUser(userData)
+
+	clock.Advance(time.Hour)
+	userData = SimulateUserUpdates(userData, rdb.Database{}, clock)
+	harvester := userData.Golems[0]
+	if userData.LastManaTick != testStart.Unix()+20 || harvester.LastEnergyTick != testStart.Unix()+20 || harvester.HarvestInfo.LastHarvestTick != testStart.Unix()+20 {
+		t.Fatalf("expected every tick at the second event +20s, got mana %d energy %d harvest %d", userData.LastManaTick, harvester.LastEnergyTick, harvester.HarvestInfo.LastHarvestTick)
+	}
+
+	// The rest are replayed on later updates rather than skipped, ending as if never capped
+	Max_Simulation_Events = previousMax
+	userData = SimulateUserUpdates(userData, rdb.Database{}, clock)
+	uncapped = SimulateUserUpdates(uncapped, rdb.Database{}, clock)
+	if caught, expected := userData.Golems[0].Cargo["ore"].Quantity, uncapped.Golems[0].Cargo["ore"].Quantity; caught != expected {
+		t.Fatalf("expected %d ore after catching up, got %d", expected, caught)
+	}
+}
@@ -0,0 +1,78 @@
+// Package gamelogic provides functions for game logic
+package gamelogic
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/brct-james/guild-golems/rdb"
+	"github.com/brct-james/guild-golems/schema"
+)
+
+// Get the mana cost of teleporting the golem along path
+// Each hop costs the ritual's mana cost plus Teleport_Mana_Per_Capacity for every unit of capacity its cargo fills
+func GetTeleportCost(golem schema.Golem, ritual schema.Ritual, path []schema.Route) float64 {
+	cargoCapacity := schema.CalculateUsedCapacity(golem.Cargo)
+	return math.Ceil(float64(len(path)) * (ritual.ManaCost + cargoCapacity*Teleport_Mana_Per_Capacity))
+}
+
+// Find the path the golem would teleport along to destinationSymbol, the fewest hops between them on the route graph
+// The golem must be at a locale and not in a blocking status, and the user must meet the entry requirements of every region crossed
+func PlanTeleport(userData schema.User, golemIndex int, destinationSymbol string, wdb rdb.Database) ([]schema.Route, error) {
+	golem := userData.Golems[golemIndex]
+	if strings.EqualFold(golem.LocationSymbol, schema.In_Transit_Location_Symbol) {
+		return nil, fmt.Errorf("%w: %s must arrive before it can be teleported", schema.ErrGolemInTransit, golem.Symbol)
+	}
+	if statusInfo, ok := schema.GolemStatuses[strings.ToLower(golem.Status)]; !ok || statusInfo.IsBlocking {
+		return nil, fmt.Errorf("%w: %s cannot be teleported while %s", schema.ErrGolemBlocked, golem.Symbol, golem.Status)
+	}
+	if strings.EqualFold(golem.LocationSymbol, destinationSymbol) {
+		return nil, fmt.Errorf("%w: %s is already at %s", schema.ErrRequirementsNotMet, golem.Symbol, destinationSymbol)
+	}
+	routes, routesErr := schema.Route_get_all_from_db(wdb)
+	if routesErr != nil {
+		return nil, fmt.Errorf("%w: routes: %v", schema.ErrWorldDataUnavailable, routesErr)
+	}
+	plan, planErr := PlanRoute(routes, golem.LocationSymbol, destinationSymbol, "hops")
+	if planErr != nil {
+		return nil, planErr
+	}
+	path, pathErr := ResolvePath(routes, golem.LocationSymbol, plan.RouteSymbols)
+	if pathErr != nil {
+		return nil, pathErr
+	}
+	regions, regionsErr := schema.Region_get_all_from_db(wdb)
+	if regionsErr != nil {
+		return nil, fmt.Errorf("%w: regions: %v", schema.ErrWorldDataUnavailable, regionsErr)
+	}
+	requirementsErr := CheckRegionEntryRequirements(userData, regions, path)
+	if requirementsErr != nil {
+		return nil, requirementsErr
+	}
+	return path, nil
+}
+
+// Spend mana to move the golem and its cargo instantly to the end of path at timestamp, drawing first from the ritual circle where it stands
+// The golem stops whatever it was doing and its orders are cleared. Returns the mana spent
+func TeleportGolem(userData *schema.User, golemIndex int, ritual schema.Ritual, path []schema.Route, timestamp int64) (float64, error) {
+	golem := &userData.Golems[golemIndex]
+	_, destinationSymbol, parseErr := schema.ParseRouteSymbol(path[len(path)-1].Symbol)
+	if parseErr != nil {
+		return 0, parseErr
+	}
+	cost := GetTeleportCost(*golem, ritual, path)
+	spendErr := spendRitualMana(userData, golem.LocationSymbol, cost)
+	if spendErr != nil {
+		return 0, spendErr
+	}
+	originSymbol := golem.LocationSymbol
+	golem.LocationSymbol = destinationSymbol
+	if !strings.EqualFold(golem.Status, "idle") {
+		golem.Status = "idle"
+		golem.IdleSince = timestamp
+	}
+	ClearGolemOrders(golem)
+	schema.AddGolemEvent(golem, timestamp, "teleported", fmt.Sprintf("Teleported from %s to %s", originSymbol, destinationSymbol))
+	return cost, nil
+}
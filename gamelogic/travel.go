@@ -2,29 +2,161 @@
 package gamelogic
 
 import (
+	"fmt"
+	"math"
 	"strings"
 	"time"
 
 	"github.com/brct-james/guild-golems/log"
 	"github.com/brct-james/guild-golems/schema"
+	"github.com/brct-james/guild-golems/timecalc"
 )
 
-// Update whether golem arrived at destination, return the updated userData
-func CalculateTravelArrived(userData schema.User) (schema.User) {
-	log.Debug.Println(log.Cyan("-- Begin CalculateTravelArrived --"))
-	log.Debug.Printf("golems: %v", userData.Golems)
-	for i, golem := range userData.Golems {
-		if strings.EqualFold(golem.Status, "traveling") {
-			// Success, traveling, check if complete
-			arrTime := time.Unix(golem.TravelInfo.ArrivalTime, 0)
-			now := time.Now()
-			if arrTime.Before(now) {
-				// Travel complete
-				log.Debug.Printf("%v before %v, setting to idle", arrTime, now)
-				userData.Golems[i].Status = "idle"
-			}
+// Deduct the cost of every route in path from the user, in coins unless a route's CostType is "mana"
+// Checks the totals before charging anything, so a failed charge leaves userData untouched
+func ChargeRouteCosts(userData *schema.User, path []schema.Route) error {
+	costErr := CheckRouteCosts(*userData, path)
+	if costErr != nil {
+		return costErr
+	}
+	plan := NewRoutePlan("", "", path)
+	userData.Mana -= float64(plan.TotalManaCost)
+	userData.Coins -= uint64(plan.TotalCoinCost)
+	return nil
+}
+
+// Check the user can afford the cost of every hop of path without charging it
+func CheckRouteCosts(userData schema.User, path []schema.Route) error {
+	plan := NewRoutePlan("", "", path)
+	if userData.Mana < float64(plan.TotalManaCost) {
+		return fmt.Errorf("%w: have %v but travel costs %d", schema.ErrInsufficientMana, userData.Mana, plan.TotalManaCost)
+	}
+	if userData.Coins < uint64(plan.TotalCoinCost) {
+		return fmt.Errorf("%w: have %d but travel costs %d", schema.ErrInsufficientCoins, userData.Coins, plan.TotalCoinCost)
+	}
+	return nil
+}
+
+// Check the user meets the entry requirements of every region path crosses into
+func CheckRegionEntryRequirements(userData schema.User, regions map[string]schema.Region, path []schema.Route) error {
+	for _, route := range path {
+		originSymbol, destinationSymbol, parseErr := schema.ParseRouteSymbol(route.Symbol)
+		if parseErr != nil {
+			return parseErr
+		}
+		originRegion, _ := schema.GetRegionSymbolForLocale(regions, originSymbol)
+		destinationRegion, found := schema.GetRegionSymbolForLocale(regions, destinationSymbol)
+		if !found || originRegion == destinationRegion {
+			continue
+		}
+		entryErr := checkRegionEntry(userData, regions, destinationRegion)
+		if entryErr != nil {
+			return entryErr
+		}
+	}
+	return nil
+}
+
+// Check the user meets the entry requirements of the region containing localeSymbol
+func CheckLocaleEntryRequirements(userData schema.User, regions map[string]schema.Region, localeSymbol string) error {
+	regionSymbol, found := schema.GetRegionSymbolForLocale(regions, localeSymbol)
+	if !found {
+		return nil
+	}
+	return checkRegionEntry(userData, regions, regionSymbol)
+}
+
+// Check the user meets the entry requirements of regionSymbol
+func checkRegionEntry(userData schema.User, regions map[string]schema.Region, regionSymbol string) error {
+	requirements := regions[regionSymbol].EntryRequirements
+	if schema.GetTitleRank(userData.Title) < schema.GetTitleRank(requirements.MinTitle) {
+		return fmt.Errorf("%w: entering %s requires the title %s", schema.ErrRequirementsNotMet, regionSymbol, requirements.MinTitle)
+	}
+	if requirements.RequiredRitualSymbol != "" && !schema.DoesUserKnowRitual(userData, requirements.RequiredRitualSymbol) {
+		return fmt.Errorf("%w: entering %s requires knowing %s", schema.ErrRequirementsNotMet, regionSymbol, requirements.RequiredRitualSymbol)
+	}
+	return nil
+}
+
+// Charge the route cost and send the golem along route with travelStatus, e.g. traveling or delivering, departing at startTime
+func StartGolemTravel(userData *schema.User, golemIndex int, route schema.Route, travelStatus string, startTime time.Time) error {
+	return StartGolemPath(userData, golemIndex, []schema.Route{route}, travelStatus, startTime)
+}
+
+// Charge the cost of every hop up front and send the golem along the first, the rest are followed by CompleteTravelHop
+// The golem is in transit until CompleteTravelHop sets its location to the destination of each hop
+func StartGolemPath(userData *schema.User, golemIndex int, path []schema.Route, travelStatus string, startTime time.Time) error {
+	if len(path) < 1 {
+		return fmt.Errorf("%w: path must contain at least one route", schema.ErrRouteUnavailable)
+	}
+	golem := &userData.Golems[golemIndex]
+	_, _, parseErr := schema.ParseRouteSymbol(path[0].Symbol)
+	if parseErr != nil {
+		return parseErr
+	}
+	costErr := ChargeRouteCosts(userData, path)
+	if costErr != nil {
+		return costErr
+	}
+	golem.TravelInfo.RemainingRoutes = make([]string, 0, len(path)-1)
+	for _, route := range path[1:] {
+		golem.TravelInfo.RemainingRoutes = append(golem.TravelInfo.RemainingRoutes, route.Symbol)
+	}
+	startTravelHop(golem, path[0], startTime)
+	golem.Status = travelStatus
+	return nil
+}
+
+// Put the golem in transit along route, departing at departureTime
+func startTravelHop(golem *schema.Golem, route schema.Route, departureTime time.Time) {
+	routeOrigin, routeDestination, _ := schema.ParseRouteSymbol(route.Symbol)
+	golem.TravelInfo.OriginSymbol = routeOrigin
+	golem.TravelInfo.DestinationSymbol = routeDestination
+	golem.TravelInfo.DepartureTime = departureTime.Unix()
+	golem.TravelInfo.ArrivalTime = timecalc.AddSecondsToTimestamp(departureTime, route.TravelTime).Unix()
+	golem.TravelInfo.Progress = 0
+	// Danger outcomes are rolled on arrival
+	golem.TravelInfo.RouteDanger = route.DangerLevel
+	golem.LocationSymbol = schema.In_Transit_Location_Symbol
+}
+
+// Get the fraction of the golem's trip completed at now, between 0 and 1
+func CalculateTravelProgress(travelInfo schema.GolemTravelInfo, now int64) float64 {
+	duration := travelInfo.ArrivalTime - travelInfo.DepartureTime
+	if duration <= 0 {
+		return 1
+	}
+	return math.Max(0, math.Min(1, float64(now - travelInfo.DepartureTime)/float64(duration)))
+}
+
+// Finish the golem's current hop at its arrival time, starting the next hop of multi-hop paths
+// At the end of the path deliveries are unloaded and the golem set idle, routes is only needed if hops remain
+func CompleteTravelHop(userData *schema.User, golemIndex int, routes map[string]schema.Route) {
+	golem := &userData.Golems[golemIndex]
+	// Hop complete, only now is the golem at its destination
+	golem.LocationSymbol = golem.TravelInfo.DestinationSymbol
+	golem.TravelInfo.Progress = 1
+	schema.AddGolemEvent(golem, golem.TravelInfo.ArrivalTime, "arrived", fmt.Sprintf("Arrived at %s", golem.TravelInfo.DestinationSymbol))
+	GrantGolemExperience(golem, Experience_Per_Trip, golem.TravelInfo.ArrivalTime)
+	if len(golem.TravelInfo.RemainingRoutes) > 0 {
+		nextRoute, ok := routes[golem.TravelInfo.RemainingRoutes[0]]
+		if ok {
+			golem.TravelInfo.RemainingRoutes = golem.TravelInfo.RemainingRoutes[1:]
+			// The next hop departs the moment the last one arrived
+			startTravelHop(golem, nextRoute, time.Unix(golem.TravelInfo.ArrivalTime, 0))
+			return
+		}
+		log.Error.Printf("Golem %s path contains unknown route %s, stopping at %s", golem.Symbol, golem.TravelInfo.RemainingRoutes[0], golem.LocationSymbol)
+		golem.TravelInfo.RemainingRoutes = make([]string, 0)
+	}
+	if strings.EqualFold(golem.Status, "delivering") {
+		// Deposit delivery at the destination locale inventory
+		depositErr := UnloadAllGolemCargo(userData, golemIndex)
+		if depositErr != nil {
+			log.Error.Printf("Could not deposit delivery for golem %s at %s: %v", golem.Symbol, golem.LocationSymbol, depositErr)
 		}
 	}
-	log.Debug.Println(log.Cyan("-- End CalculateTravelArrived --"))
-	return userData
-}
\ No newline at end of file
+	log.Debug.Printf("Golem %s arrived at %s, setting to idle", golem.Symbol, golem.LocationSymbol)
+	golem.Status = "idle"
+	golem.IdleSince = golem.TravelInfo.ArrivalTime
+}
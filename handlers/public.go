@@ -7,10 +7,12 @@ import (
 	"net/http"
 
 	"github.com/brct-james/guild-golems/auth"
+	"github.com/brct-james/guild-golems/gamelogic"
 	"github.com/brct-james/guild-golems/log"
 	"github.com/brct-james/guild-golems/metrics"
 	"github.com/brct-james/guild-golems/rdb"
 	"github.com/brct-james/guild-golems/responses"
+	"github.com/brct-james/guild-golems/scheduler"
 	"github.com/brct-james/guild-golems/schema"
 	"github.com/gorilla/mux"
 )
@@ -98,14 +100,20 @@ func V0Status(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Cyan("-- End v0Status --"))
 }
 
+// Generate handler function for the route: /api/v0/scheduler
+// Reports when each background job last ran and how long it took
+func GenerateSchedulerStatusHandler(s *scheduler.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Debug.Println(log.Yellow("-- SchedulerStatus --"))
+		responses.SendRes(w, responses.Generic_Success, s.Status(), "")
+		log.Debug.Println(log.Cyan("-- End SchedulerStatus --"))
+	}
+}
+
 // Handler function for the route: /api/v0/leaderboards
 func LeaderboardDescriptions(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Yellow("-- LeaderboardDescriptions --"))
-	boards := make([]schema.Leaderboard, 0)
-	for _, b := range schema.Leaderboards {
-		boards = append(boards, b)
-	}
-	response := schema.GetLeaderboardDescriptionResponses(boards)
+	response := schema.GetLeaderboardDescriptionResponses(schema.GetAllLeaderboards())
 	responses.SendRes(w, responses.Generic_Success, response, "")
 	log.Debug.Println(log.Cyan("-- End LeaderboardDescriptions --"))
 }
@@ -115,7 +123,7 @@ func GetLeaderboards(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Yellow("-- GetLeaderboards --"))
 	route_vars := mux.Vars(r)
 	boardKey := route_vars["board"]
-	board, ok := schema.Leaderboards[boardKey]
+	board, ok := schema.GetLeaderboard(boardKey)
 	if !ok {
 		// Fail state, board not found
 		responses.SendRes(w, responses.Leaderboard_Not_Found, nil, "")
@@ -243,65 +251,242 @@ func UsernameClaim(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Cyan("-- End usernameClaim --"))
 }
 
-// Handler function for the route: /api/v0/locations
-func LocationsOverview(w http.ResponseWriter, r *http.Request) {
-	log.Debug.Println(log.Yellow("-- locationsOverview -- "))
+// Handler function for the route: /api/v0/world
+func WorldOverview(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- worldOverview -- "))
 	wdbSuccess, wdb := GetWdbFromCtx(w, r)
 	if !wdbSuccess {
 		return // Fail state, could not get wdb, handled by func - simply return
 	}
-	var world schema.World
-	var regions map[string]schema.Region
-	var locales map[string]schema.Locale
-	var routes map[string]schema.Route
-	var resources map[string]schema.Resource
-	var resourceNodes map[string]schema.ResourceNode
-
 	world, worldErr := schema.World_get_from_db(wdb, ".")
 	if worldErr != nil {
 		log.Error.Printf("Could not get world from DB! Err: %v", worldErr)
 		responses.SendRes(w, responses.WDB_Get_Failure, nil, "could not get world")
 		return
 	}
+	responses.SendRes(w, responses.Generic_Success, world, "")
+	log.Debug.Println(log.Cyan("-- End worldOverview -- "))
+}
+
+// Handler function for the route: /api/v0/regions
+func RegionsOverview(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- regionsOverview -- "))
+	wdbSuccess, wdb := GetWdbFromCtx(w, r)
+	if !wdbSuccess {
+		return // Fail state, could not get wdb, handled by func - simply return
+	}
+	regions, regionsErr := schema.Region_get_all_from_db(wdb)
+	if regionsErr != nil {
+		log.Error.Printf("Could not get regions from DB! Err: %v", regionsErr)
+		responses.SendRes(w, responses.WDB_Get_Failure, nil, "could not get regions")
+		return
+	}
+	responses.SendRes(w, responses.Generic_Success, regions, "")
+	log.Debug.Println(log.Cyan("-- End regionsOverview -- "))
+}
+
+// Handler function for the route: /api/v0/regions/{symbol}
+func RegionInfo(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- regionInfo -- "))
+	route_vars := mux.Vars(r)
+	symbol := route_vars["symbol"]
+	wdbSuccess, wdb := GetWdbFromCtx(w, r)
+	if !wdbSuccess {
+		return // Fail state, could not get wdb, handled by func - simply return
+	}
 	regions, regionsErr := schema.Region_get_all_from_db(wdb)
 	if regionsErr != nil {
 		log.Error.Printf("Could not get regions from DB! Err: %v", regionsErr)
 		responses.SendRes(w, responses.WDB_Get_Failure, nil, "could not get regions")
 		return
 	}
+	region, ok := regions[symbol]
+	if !ok {
+		responses.SendRes(w, responses.No_Such_Region, nil, symbol)
+		return
+	}
+	locales, localesErr := schema.Locale_get_all_from_db(wdb)
+	if localesErr != nil {
+		log.Error.Printf("Could not get locales from DB! Err: %v", localesErr)
+		responses.SendRes(w, responses.WDB_Get_Failure, nil, "could not get locales")
+		return
+	}
+	res := schema.RegionDetailResponse{
+		Region: region,
+		Locales: make(map[string]schema.Locale),
+	}
+	for _, localeSymbol := range region.LocaleSymbols {
+		if locale, ok := locales[localeSymbol]; ok {
+			res.Locales[localeSymbol] = locale
+		}
+	}
+	responses.SendRes(w, responses.Generic_Success, res, "")
+	log.Debug.Println(log.Cyan("-- End regionInfo -- "))
+}
+
+// Handler function for the route: /api/v0/locales/{symbol}
+func LocaleInfo(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- localeInfo -- "))
+	route_vars := mux.Vars(r)
+	symbol := route_vars["symbol"]
+	wdbSuccess, wdb := GetWdbFromCtx(w, r)
+	if !wdbSuccess {
+		return // Fail state, could not get wdb, handled by func - simply return
+	}
 	locales, localesErr := schema.Locale_get_all_from_db(wdb)
 	if localesErr != nil {
 		log.Error.Printf("Could not get locales from DB! Err: %v", localesErr)
 		responses.SendRes(w, responses.WDB_Get_Failure, nil, "could not get locales")
 		return
 	}
+	locale, ok := locales[symbol]
+	if !ok {
+		responses.SendRes(w, responses.No_Such_Locale, nil, symbol)
+		return
+	}
+	regions, regionsErr := schema.Region_get_all_from_db(wdb)
+	if regionsErr != nil {
+		log.Error.Printf("Could not get regions from DB! Err: %v", regionsErr)
+		responses.SendRes(w, responses.WDB_Get_Failure, nil, "could not get regions")
+		return
+	}
 	routes, routesErr := schema.Route_get_all_from_db(wdb)
 	if routesErr != nil {
 		log.Error.Printf("Could not get routes from DB! Err: %v", routesErr)
 		responses.SendRes(w, responses.WDB_Get_Failure, nil, "could not get routes")
 		return
 	}
+	resourceNodes, resourceNodesErr := schema.ResourceNode_get_all_from_db(wdb)
+	if resourceNodesErr != nil {
+		log.Error.Printf("Could not get resourceNodes from DB! Err: %v", resourceNodesErr)
+		responses.SendRes(w, responses.WDB_Get_Failure, nil, "could not get resourceNodes")
+		return
+	}
+	resourceNodeStates, resourceNodeStatesErr := gamelogic.GetCurrentNodeStates(wdb, resourceNodes, gamelogic.GameClock.Now().Unix())
+	if resourceNodeStatesErr != nil {
+		log.Error.Printf("Could not get resourceNodeStates from DB! Err: %v", resourceNodeStatesErr)
+		responses.SendRes(w, responses.WDB_Get_Failure, nil, "could not get resourceNodeStates")
+		return
+	}
+	regionSymbol, _ := schema.GetRegionSymbolForLocale(regions, symbol)
+	res := schema.LocaleDetailResponse{
+		Locale: locale,
+		RegionSymbol: regionSymbol,
+		Routes: make(map[string]schema.Route),
+		ResourceNodes: make(map[string]schema.ResourceNode),
+		ResourceNodeStates: make(map[string]schema.ResourceNodeState),
+	}
+	for _, routeSymbol := range locale.RouteSymbols {
+		if route, ok := routes[routeSymbol]; ok {
+			res.Routes[routeSymbol] = route
+		}
+	}
+	for _, nodeSymbol := range locale.ResourceNodeSymbols {
+		if node, ok := resourceNodes[nodeSymbol]; ok {
+			res.ResourceNodes[nodeSymbol] = node
+		}
+		if state, ok := resourceNodeStates[nodeSymbol]; ok {
+			res.ResourceNodeStates[nodeSymbol] = state
+		}
+	}
+	responses.SendRes(w, responses.Generic_Success, res, "")
+	log.Debug.Println(log.Cyan("-- End localeInfo -- "))
+}
+
+// Handler function for the route: /api/v0/resources
+func ResourcesOverview(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- resourcesOverview -- "))
+	wdbSuccess, wdb := GetWdbFromCtx(w, r)
+	if !wdbSuccess {
+		return // Fail state, could not get wdb, handled by func - simply return
+	}
 	resources, resourcesErr := schema.Resource_get_all_from_db(wdb)
 	if resourcesErr != nil {
 		log.Error.Printf("Could not get resources from DB! Err: %v", resourcesErr)
 		responses.SendRes(w, responses.WDB_Get_Failure, nil, "could not get resources")
 		return
 	}
-	resourceNodes, resourceNodesErr := schema.ResourceNode_get_all_from_db(wdb)
-	if resourceNodesErr != nil {
-		log.Error.Printf("Could not get resourceNodes from DB! Err: %v", resourceNodesErr)
-		responses.SendRes(w, responses.WDB_Get_Failure, nil, "could not get resourceNodes")
+	responses.SendRes(w, responses.Generic_Success, resources, "")
+	log.Debug.Println(log.Cyan("-- End resourcesOverview -- "))
+}
+
+// Handler function for the route: /api/v0/recipes
+func RecipesOverview(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- recipesOverview -- "))
+	wdbSuccess, wdb := GetWdbFromCtx(w, r)
+	if !wdbSuccess {
+		return // Fail state, could not get wdb, handled by func - simply return
+	}
+	recipes, recipesErr := schema.Recipe_get_all_from_db(wdb)
+	if recipesErr != nil {
+		log.Error.Printf("Could not get recipes from DB! Err: %v", recipesErr)
+		responses.SendRes(w, responses.WDB_Get_Failure, nil, "could not get recipes")
+		return
+	}
+	responses.SendRes(w, responses.Generic_Success, recipes, "")
+	log.Debug.Println(log.Cyan("-- End recipesOverview -- "))
+}
+
+// Handler function for the route: /api/v0/blueprints
+func BlueprintsOverview(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- blueprintsOverview -- "))
+	wdbSuccess, wdb := GetWdbFromCtx(w, r)
+	if !wdbSuccess {
+		return // Fail state, could not get wdb, handled by func - simply return
+	}
+	blueprints, blueprintsErr := schema.Blueprint_get_all_from_db(wdb)
+	if blueprintsErr != nil {
+		log.Error.Printf("Could not get blueprints from DB! Err: %v", blueprintsErr)
+		responses.SendRes(w, responses.WDB_Get_Failure, nil, "could not get blueprints")
 		return
 	}
+	responses.SendRes(w, responses.Generic_Success, blueprints, "")
+	log.Debug.Println(log.Cyan("-- End blueprintsOverview -- "))
+}
 
-	res := schema.WorldSummaryResponse{
-		World: world,
-		Regions: regions,
-		Locales: locales,
-		Routes: routes,
-		Resources: resources,
-		ResourceNodes: resourceNodes,
+// Handler function for the route: /api/v0/routes/plan?from=&to=&optimize=
+// optimize is one of time (default), cost, danger or hops
+func PlanRoute(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- planRoute -- "))
+	wdbSuccess, wdb := GetWdbFromCtx(w, r)
+	if !wdbSuccess {
+		return // Fail state, could not get wdb, handled by func - simply return
 	}
-	responses.SendRes(w, responses.Generic_Success, res, "")
-	log.Debug.Println(log.Cyan("-- End locationsOverview -- "))
-}
\ No newline at end of file
+	query := r.URL.Query()
+	fromSymbol := query.Get("from")
+	toSymbol := query.Get("to")
+	optimize := query.Get("optimize")
+	if optimize == "" {
+		optimize = "time"
+	}
+	if fromSymbol == "" || toSymbol == "" {
+		responses.SendRes(w, responses.Bad_Request, nil, "'from' and 'to' query parameters required")
+		return
+	}
+	locales, localesErr := schema.Locale_get_all_from_db(wdb)
+	if localesErr != nil {
+		log.Error.Printf("Could not get locales from DB! Err: %v", localesErr)
+		responses.SendRes(w, responses.WDB_Get_Failure, nil, "could not get locales")
+		return
+	}
+	for _, localeSymbol := range []string{fromSymbol, toSymbol} {
+		if _, ok := locales[localeSymbol]; !ok {
+			responses.SendRes(w, responses.No_Such_Locale, nil, localeSymbol)
+			return
+		}
+	}
+	routes, routesErr := schema.Route_get_all_from_db(wdb)
+	if routesErr != nil {
+		log.Error.Printf("Could not get routes from DB! Err: %v", routesErr)
+		responses.SendRes(w, responses.WDB_Get_Failure, nil, "could not get routes")
+		return
+	}
+	plan, planErr := gamelogic.PlanRoute(routes, fromSymbol, toSymbol, optimize)
+	if planErr != nil {
+		log.Debug.Printf("Could not plan route: %v", planErr)
+		sendGameErrorRes(w, planErr)
+		return
+	}
+	responses.SendRes(w, responses.Generic_Success, plan, "")
+	log.Debug.Println(log.Cyan("-- End planRoute -- "))
+}
@@ -6,9 +6,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
-	"time"
+	"sync"
 
 	"github.com/brct-james/guild-golems/auth"
 	"github.com/brct-james/guild-golems/gamelogic"
@@ -17,7 +18,6 @@ import (
 	"github.com/brct-james/guild-golems/rdb"
 	"github.com/brct-james/guild-golems/responses"
 	"github.com/brct-james/guild-golems/schema"
-	"github.com/brct-james/guild-golems/timecalc"
 	"github.com/gorilla/mux"
 )
 
@@ -30,117 +30,36 @@ const (
 	WorldDBContext
 )
 
-// Gets route for target_route if in the locale specified by locale_path
-func getTargetRouteFromLocale(w http.ResponseWriter, r *http.Request, locale_path string, target_route string) (bool, schema.Route) {
-	// Get wdb
-	wdbSuccess, wdb := GetWdbFromCtx(w, r)
-	if !wdbSuccess {
-		log.Debug.Printf("Could not get wdb from ctx")
-		return false, schema.Route{} // Fail state, could not get wdb, handled by func - simply return
-	}
-	// Success state, got wdb
-	// Get locale data from db
-	cur_locale, localeErr := schema.Locale_get_from_db(wdb, locale_path)
-	if localeErr != nil {
-		log.Error.Printf("Could not get locale %s from db: %v", locale_path, localeErr)
-		responses.SendRes(w, responses.WDB_Get_Failure, nil, "locale corresponding to specified golem's location could not be gotten")
-		return false, schema.Route{}
-	}
-	// Find routes for relevant locale
-	if len(cur_locale.RouteSymbols) < 1 {
-		// Fail case, no routes found
-		responses.SendRes(w, responses.No_Available_Routes, nil, "")
-		log.Error.Printf("Golem has no available routes! locale_path: %s | target_route: %s | Locale: %v", locale_path, target_route, cur_locale)
-		return false, schema.Route{}
-	}
-	foundTargetRoute, route := getRouteDataIfTargetRouteInCurLocaleRoutes(w, r, wdb, cur_locale, target_route)
-	return foundTargetRoute, route
-}
-
-// Gets route for target_route if in cur_locale's defined routes
-func getRouteDataIfTargetRouteInCurLocaleRoutes(w http.ResponseWriter, r *http.Request, wdb rdb.Database, cur_locale schema.Locale, target_route string) (bool, schema.Route) {
-	// Now check for target_route in curLocale.Routes
-	var target_route_symbol string
-	routeFound := false
-	for _, routeSymbol := range cur_locale.RouteSymbols {
-		if strings.EqualFold(routeSymbol, target_route) {
-			// Success case, found target_route in curLocale.Routes
-			log.Debug.Printf("Found target_route in curLocal.Routes. route.Symbol: %s, target_route.(string): %s", routeSymbol, target_route)
-			routeFound = true
-			target_route_symbol = routeSymbol
-			break
+// Execute the status change in reqBody as an order for the golem, then save and respond
+// A direct status change replaces any queued orders
+func executeGolemStatusChange(w http.ResponseWriter, r *http.Request, reqBody schema.GolemStatusUpdateBody, userData *schema.User, golemIndex int) {
+	instructions := make(map[string]interface{})
+	if reqBody.Instructions != nil {
+		gotInstructions, statusInstructions := getStatusInstructions(w, reqBody)
+		if !gotInstructions {
+			return // Fail state, handled by func, return
 		}
+		instructions = statusInstructions
 	}
-	if !routeFound {
-		// Fail case, target_route not in curLocale.Routes
-		log.Debug.Printf("target_route not in curLocale.Routes")
-		responses.SendRes(w, responses.Target_Route_Unavailable, nil, "")
-		return false, schema.Route{}
+	wdbSuccess, wdb := GetWdbFromCtx(w, r)
+	if !wdbSuccess {
+		return // Fail state, could not get wdb, handled by func - simply return
 	}
-
-	// Get route data
-	route_path := fmt.Sprintf(".%s", target_route_symbol)
-	cur_route, route_err := schema.Route_get_from_db(wdb, route_path)
-	if route_err != nil {
-		log.Error.Printf("Could not get route %s from db: %v", route_path, route_err)
-		responses.SendRes(w, responses.WDB_Get_Failure, nil, "specified route could not be gotten")
-		return false, schema.Route{}
+	targetGolem := &userData.Golems[golemIndex]
+	gamelogic.ClearGolemOrders(targetGolem)
+	order := schema.GolemOrder{Action: reqBody.NewStatus, Instructions: instructions}
+	orderErr := gamelogic.ExecuteGolemOrder(userData, golemIndex, order, wdb, gamelogic.GameClock.Now())
+	if orderErr != nil {
+		log.Debug.Printf("Golem %s could not change status to %s: %v", targetGolem.Symbol, reqBody.NewStatus, orderErr)
+		sendGameErrorRes(w, orderErr)
+		return
 	}
-	return true, cur_route
-}
-
-func executeGolemStatusChange(w http.ResponseWriter, r *http.Request, reqBody schema.GolemStatusUpdateBody, userData *schema.User, targetGolem *schema.Golem) {
-	switch reqBody.NewStatus {
-	case "idle":
-		targetGolem.Status = "idle"
-		savedToDb := GetUDBAndSaveUserToDB(w, r, *userData)
-		if !savedToDb {
-			return // Fail state, handled by func, return
-		}
-		responses.SendRes(w, responses.Generic_Success, targetGolem, "")
-	case "harvesting":
-		// reqBody.Instructions
-		//TODO: this
-		responses.SendRes(w, responses.Generic_Success, targetGolem, "")
-	case "traveling":
-		// Check for all expected instructions
-		// Convert Instructions to map with string keys
-		statusInstructions := reqBody.Instructions.(map[string]interface{})
-		routeInInstructions, target_route := stringKeyInMap("route", statusInstructions)
-		if !routeInInstructions {
-			// Fail case
-			log.Debug.Printf("'route' key required for 'traveling' status")
-			responses.SendRes(w, responses.Bad_Request, nil, "'route' key required for 'traveling' status")
-			return
-		}
-		// Get routes for golem locale
-		locale_path := fmt.Sprintf(".%s", targetGolem.LocationSymbol)
-		gotRoute, cur_route := getTargetRouteFromLocale(w, r, locale_path, target_route.(string))
-		if !gotRoute {
-			return // Fail state, handled by func, return
-		}
-		// Get destination from cur_route.Symbol
-		log.Debug.Printf("cur_route.Symbol: %v", cur_route.Symbol)
-		destinationSymbol := strings.Split(cur_route.Symbol, "|")[1]
-		// Start travel
-		// May set route danger as well later, to have a result calculated after travel completed
-		targetGolem.TravelInfo.ArrivalTime = timecalc.AddSecondsToTimestamp(time.Now(), cur_route.TravelTime).Unix()
-		targetGolem.Status = "traveling"
-		targetGolem.LocationSymbol = destinationSymbol
-		// Save to DB
-		savedToDb := GetUDBAndSaveUserToDB(w, r, *userData)
-		if !savedToDb {
-			return // Fail state, handled by func, return
-		}
-		responses.SendRes(w, responses.Generic_Success, targetGolem, "")
-	case "invoking":
-		//TODO: this
-		responses.SendRes(w, responses.Generic_Success, targetGolem, "")
-	default:
-		// Error state, newStatus passed validation but not caught by switch statement
-		//TODO: this
-		responses.SendRes(w, responses.Generic_Failure, nil, "Unexpected Error state, newStatus passed validation but not caught by switch statement. Contact developer")
+	// Save to DB
+	savedToDb := GetUDBAndSaveUserToDB(w, r, *userData)
+	if !savedToDb {
+		return // Fail state, handled by func, return
 	}
+	responses.SendRes(w, responses.Generic_Success, targetGolem, "")
 }
 
 // Attempt to get validation context
@@ -171,6 +90,31 @@ func GenerateHandlerMiddlewareFunc(udb rdb.Database, wdb rdb.Database) func(http
 	}
 }
 
+// Per-user locks, keyed by token, held for the whole of each secure request
+var userLocks sync.Map
+
+// Generates middleware func to serve one secure request per user at a time
+// secureGetUser replays events drawing on shared resource nodes before saving the whole user, so concurrent requests
+// from one user would replay the same events twice and the last save would win. Must run after token validation
+func GenerateUserLockMiddlewareFunc() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			log.Debug.Println(log.Yellow("-- GenerateUserLockMiddlewareFunc --"))
+			userInfo, ok := r.Context().Value(auth.ValidationContext).(auth.ValidationPair)
+			if !ok {
+				// Let secureGetUser report the missing validation pair
+				next.ServeHTTP(w, r)
+				return
+			}
+			lock, _ := userLocks.LoadOrStore(userInfo.Token, &sync.Mutex{})
+			lock.(*sync.Mutex).Lock()
+			defer lock.(*sync.Mutex).Unlock()
+			next.ServeHTTP(w, r)
+			log.Debug.Println(log.Cyan("-- End GenerateUserLockMiddlewareFunc --"))
+		})
+	}
+}
+
 // Get User from Middleware and DB
 // Returns: OK, userData, udb, userAuthPair
 func secureGetUser(w http.ResponseWriter, r *http.Request) (bool, schema.User, rdb.Database, auth.ValidationPair) {
@@ -206,64 +150,22 @@ func secureGetUser(w http.ResponseWriter, r *http.Request) (bool, schema.User, r
 		responses.SendRes(w, responses.User_Not_Found, nil, userNotFoundMsg)
 		return false, schema.User{}, rdb.Database{}, auth.ValidationPair{}
 	}
-	// Success case
-	thisUser = gamelogic.CalculateUserUpdates(thisUser)
-	return true, thisUser, udb, userInfo
-}
-
-// Check user data for ritual in list of known rituals
-func doesUserKnowRitual(userData schema.User, ritualKey string) (bool) {
-	for _, ritual := range userData.KnownRituals {
-		if strings.EqualFold(ritual, ritualKey) {
-			// success state - user knows ritual
-			return true
-		}
-	}
-	// fail state - user doesnt know ritual
-	return false
-}
-
-// Create new golem for user in database, if able, of particular archetype
-func createNewGolemInDB(w http.ResponseWriter, r *http.Request, udb rdb.Database, userData schema.User, archetype string, ritualName string, startingStatus string, capacity float64) (bool) {
-	knowsRitual := doesUserKnowRitual(userData, ritualName)
-	if !knowsRitual {
-		responses.SendRes(w, responses.Ritual_Not_Known, nil, "")
-		return false
+	// Success case, apply game logic updates since last call and persist them
+	// GenerateUserLockMiddlewareFunc keeps other requests from this user from replaying the same events meanwhile
+	wdbSuccess, wdb := GetWdbFromCtx(w, r)
+	if !wdbSuccess {
+		return false, schema.User{}, rdb.Database{}, auth.ValidationPair{} // Fail state, could not get wdb, handled by func - simply return
 	}
-	success, newManaValue := gamelogic.TryManaPurchase(w, userData.Mana, 600)
-	if !success {
-		return false // Failure states handled by TryManaPurchase, return false for failure
-	}
-	userData.Mana = newManaValue
-	// // At this time not able to delete or lose golems so using len() on filtered list of golems is fine
-	// var sameArchetypeGolemIds []int
-	// for _, golem := range schema.FilterGolemListByArchetype(userData.Golems, archetype) {
-	// 	golemId, err := strconv.Atoi(strings.Split(golem.Symbol, "-")[1])
-	// 	if err != nil {
-	// 		// Failure state - could not convert id from string to int
-	// 		responses.SendRes(w, responses.Generic_Failure, nil, "Internal server error in createNewGolemInDB")
-	// 		return false
-	// 	}
-	// 	sameArchetypeGolemIds = append(sameArchetypeGolemIds, golemId)
-	// }
-	// // todo: sort and use ids[-1]+1 for newGolemId
-	var newGolemId int = len(schema.FilterGolemListByArchetype(userData.Golems, archetype))
-	
-	newGolemSymbol := fmt.Sprintf("%s-%d", schema.GolemArchetypes[archetype].Abbreviation, newGolemId)
-	newGolem := schema.NewGolem(newGolemSymbol, archetype, startingStatus, capacity)
-	userData.Golems = append(userData.Golems, newGolem)
-	saveUserErr := SaveUserToDB(udb, userData)
+	thisUser = gamelogic.CalculateUserUpdates(thisUser, wdb)
+	saveUserErr := SaveUserToDB(udb, thisUser)
 	if saveUserErr != nil {
 		// fail state - could not save
-		saveUserErrMsg := fmt.Sprintf("in createNewGolemInDB | Username: %v | SaveUserToDB failed, dbSaveResult: %v", userData.Username, saveUserErr)
+		saveUserErrMsg := fmt.Sprintf("in secureGetUser | Username: %v | SaveUserToDB failed, dbSaveResult: %v", thisUser.Username, saveUserErr)
 		log.Debug.Println(saveUserErrMsg)
 		responses.SendRes(w, responses.DB_Save_Failure, nil, saveUserErrMsg)
-		return false
+		return false, schema.User{}, rdb.Database{}, auth.ValidationPair{}
 	}
-	// Updated successfully
-	log.Debug.Printf("Spawned new %s golem for username %s", archetype, userData.Username)
-	responses.SendRes(w, responses.Generic_Success, newGolem, "")
-	return true
+	return true, thisUser, udb, userInfo
 }
 
 // Remove trailing s if one exists
@@ -289,13 +191,298 @@ func getRequestBodyForGolemStatusUpdate(w http.ResponseWriter, r *http.Request)
 	return true, body
 }
 
-func stringKeyInMap(key string, dict map[string]interface{}) (bool, interface{}) {
-	if val, ok := dict[key]; ok {
-		// yes, key in map
-		return true, val
+// Get body for golem load and unload requests
+func getRequestBodyForGolemCargoTransfer(w http.ResponseWriter, r *http.Request) (bool, schema.GolemCargoTransferBody) {
+	var body schema.GolemCargoTransferBody
+	decoder := json.NewDecoder(r.Body)
+	if decodeErr := decoder.Decode(&body); decodeErr != nil || len(body.Resources) < 1 {
+		// Fail case, could not decode
+		responses.SendRes(w, responses.Bad_Request, nil, "Could not decode request body, expected {\"resources\": {\"SYMBOL\": quantity}}")
+		log.Debug.Printf("Error in getRequestBodyForGolemCargoTransfer: %v", decodeErr)
+		return false, schema.GolemCargoTransferBody{}
 	}
-	// no, key not in map
-	return false, nil
+	// Success case, decoded request
+	return true, body
+}
+
+// Get body for golem order queue requests
+func getRequestBodyForGolemOrders(w http.ResponseWriter, r *http.Request) (bool, schema.GolemOrdersBody) {
+	var body schema.GolemOrdersBody
+	decoder := json.NewDecoder(r.Body)
+	if decodeErr := decoder.Decode(&body); decodeErr != nil || len(body.Orders) < 1 {
+		// Fail case, could not decode
+		responses.SendRes(w, responses.Bad_Request, nil, "Could not decode request body, expected {\"orders\": [{\"action\": ..., \"instructions\": {...}}], \"repeat\": false}")
+		log.Debug.Printf("Error in getRequestBodyForGolemOrders: %v", decodeErr)
+		return false, schema.GolemOrdersBody{}
+	}
+	// Success case, decoded request
+	return true, body
+}
+
+// Get body for rituals, an empty body is allowed unless the ritual needs a golem or destination
+func getRequestBodyForRitual(w http.ResponseWriter, r *http.Request, ritual schema.Ritual) (bool, schema.RitualBody) {
+	var body schema.RitualBody
+	decoder := json.NewDecoder(r.Body)
+	decodeErr := decoder.Decode(&body)
+	if decodeErr == io.EOF {
+		decodeErr = nil
+	}
+	needsGolem := schema.RitualTargetsGolem(ritual)
+	needsDestination := strings.EqualFold(ritual.Effect.Type, "teleport")
+	if decodeErr != nil || (needsGolem && body.GolemSymbol == "") || (needsDestination && body.DestinationSymbol == "") {
+		// Fail case, could not decode
+		responses.SendRes(w, responses.Bad_Request, nil, "Could not decode request body, expected {\"golem_symbol\": ..., \"locale_symbol\": ..., \"destination_symbol\": ..., \"repetitions\": 1}, golem_symbol is required for rituals acting on a golem and destination_symbol for teleport")
+		log.Debug.Printf("Error in getRequestBodyForRitual: %v", decodeErr)
+		return false, schema.RitualBody{}
+	}
+	if body.Repetitions == 0 {
+		body.Repetitions = 1
+	}
+	// Success case, decoded request
+	return true, body
+}
+
+// Get body for requests that may take resource costs from an inventory, an empty body uses the starting locale
+func getRequestBodyForPurchase(w http.ResponseWriter, r *http.Request) (bool, schema.PurchaseBody) {
+	var body schema.PurchaseBody
+	decoder := json.NewDecoder(r.Body)
+	if decodeErr := decoder.Decode(&body); decodeErr != nil && decodeErr != io.EOF {
+		// Fail case, could not decode
+		responses.SendRes(w, responses.Bad_Request, nil, "Could not decode request body, expected {\"locale_symbol\": ...} or no body")
+		log.Debug.Printf("Error in getRequestBodyForPurchase: %v", decodeErr)
+		return false, schema.PurchaseBody{}
+	}
+	if body.LocaleSymbol == "" {
+		body.LocaleSymbol = schema.Starting_Locale_Symbol
+	}
+	// Success case, decoded request
+	return true, body
+}
+
+// Send the response matching an error from gamelogic, e.g. inventory, cargo, market and order helpers
+func sendGameErrorRes(w http.ResponseWriter, err error) {
+	if errors.Is(err, schema.ErrInsufficientQuantity) {
+		responses.SendRes(w, responses.Insufficient_Resources, nil, err.Error())
+		return
+	}
+	if errors.Is(err, schema.ErrInsufficientCapacity) {
+		responses.SendRes(w, responses.Insufficient_Capacity, nil, err.Error())
+		return
+	}
+	if errors.Is(err, schema.ErrInsufficientMana) {
+		responses.SendRes(w, responses.Not_Enough_Mana, nil, err.Error())
+		return
+	}
+	if errors.Is(err, schema.ErrInsufficientCoins) {
+		responses.SendRes(w, responses.Not_Enough_Coins, nil, err.Error())
+		return
+	}
+	if errors.Is(err, schema.ErrResourceNotTraded) {
+		responses.SendRes(w, responses.Resource_Not_Traded, nil, err.Error())
+		return
+	}
+	if errors.Is(err, schema.ErrRouteUnavailable) {
+		responses.SendRes(w, responses.Target_Route_Unavailable, nil, err.Error())
+		return
+	}
+	if errors.Is(err, schema.ErrNoPathFound) {
+		responses.SendRes(w, responses.No_Path_Found, nil, err.Error())
+		return
+	}
+	if errors.Is(err, schema.ErrResourceNodeUnavailable) {
+		responses.SendRes(w, responses.Target_Resource_Node_Unavailable, nil, err.Error())
+		return
+	}
+	if errors.Is(err, schema.ErrNoSuchRecipe) {
+		responses.SendRes(w, responses.No_Such_Recipe, nil, err.Error())
+		return
+	}
+	if errors.Is(err, schema.ErrNoSuchBlueprint) {
+		responses.SendRes(w, responses.No_Such_Blueprint, nil, err.Error())
+		return
+	}
+	if errors.Is(err, schema.ErrStatusNotAllowed) {
+		responses.SendRes(w, responses.New_Status_Not_Allowed, nil, err.Error())
+		return
+	}
+	if errors.Is(err, schema.ErrNotEnoughInvokers) {
+		responses.SendRes(w, responses.Not_Enough_Invokers, nil, err.Error())
+		return
+	}
+	if errors.Is(err, schema.ErrRitualUnimplemented) {
+		responses.SendRes(w, responses.Unimplemented, nil, err.Error())
+		return
+	}
+	if errors.Is(err, schema.ErrGolemExhausted) {
+		responses.SendRes(w, responses.Golem_Exhausted, nil, err.Error())
+		return
+	}
+	if errors.Is(err, schema.ErrGolemBroken) {
+		responses.SendRes(w, responses.Golem_Broken, nil, err.Error())
+		return
+	}
+	if errors.Is(err, schema.ErrGolemBlocked) {
+		responses.SendRes(w, responses.Golem_In_Blocking_Status, nil, err.Error())
+		return
+	}
+	if errors.Is(err, schema.ErrGolemInTransit) {
+		responses.SendRes(w, responses.Golem_In_Blocking_Status, nil, err.Error())
+		return
+	}
+	if errors.Is(err, schema.ErrWorldDataUnavailable) {
+		responses.SendRes(w, responses.WDB_Get_Failure, nil, err.Error())
+		return
+	}
+	if errors.Is(err, schema.ErrRequirementsNotMet) {
+		responses.SendRes(w, responses.Requirements_Not_Met, nil, err.Error())
+		return
+	}
+	responses.SendRes(w, responses.Bad_Request, nil, err.Error())
+}
+
+// Get the set of locale symbols where the user has a merchant that is not in a blocking status (e.g. traveling)
+func getMerchantLocales(userData schema.User) (map[string]bool) {
+	merchantLocales := make(map[string]bool)
+	for _, golem := range schema.FilterGolemListByArchetype(userData.Golems, "merchant") {
+		if statusInfo, ok := schema.GolemStatuses[golem.Status]; ok && !statusInfo.IsBlocking {
+			merchantLocales[golem.LocationSymbol] = true
+		}
+	}
+	return merchantLocales
+}
+
+// Get body for merchant buy and sell requests
+func getRequestBodyForMarketOrder(w http.ResponseWriter, r *http.Request) (bool, schema.MarketOrderBody) {
+	var body schema.MarketOrderBody
+	decoder := json.NewDecoder(r.Body)
+	if decodeErr := decoder.Decode(&body); decodeErr != nil || body.ResourceSymbol == "" {
+		// Fail case, could not decode
+		responses.SendRes(w, responses.Bad_Request, nil, "Could not decode request body, expected {\"resource_symbol\": \"SYMBOL\", \"quantity\": quantity}")
+		log.Debug.Printf("Error in getRequestBodyForMarketOrder: %v", decodeErr)
+		return false, schema.MarketOrderBody{}
+	}
+	// Success case, decoded request
+	return true, body
+}
+
+// Have the specified merchant buy or sell at the market in its current locale
+func merchantTrade(w http.ResponseWriter, r *http.Request, action string) {
+	route_vars := mux.Vars(r)
+	symbol := route_vars["symbol"]
+	OK, userData, _, _ := secureGetUser(w, r)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	wdbSuccess, wdb := GetWdbFromCtx(w, r)
+	if !wdbSuccess {
+		return // Fail state, could not get wdb, handled by func - simply return
+	}
+	// Find golem with symbol
+	found, golemIndex := schema.FindIndexOfGolemWithSymbol(userData.Golems, symbol)
+	if !found {
+		// Not Found
+		responses.SendRes(w, responses.No_Golem_Found, nil, "")
+		return
+	}
+	merchant := userData.Golems[golemIndex]
+	if !schema.DoesGolemArchetypeMatch(merchant, "merchant") {
+		responses.SendRes(w, responses.Golem_Wrong_Archetype, nil, "only merchants can trade")
+		return
+	}
+	if statusInfo, ok := schema.GolemStatuses[merchant.Status]; !ok || statusInfo.IsBlocking {
+		responses.SendRes(w, responses.Golem_In_Blocking_Status, nil, merchant.Status)
+		return
+	}
+	gotReqBody, reqBody := getRequestBodyForMarketOrder(w, r)
+	if !gotReqBody {
+		return // Fail state, handled by func, return
+	}
+	// Check the market exists before trading, the trade itself rereads it atomically
+	market_path := fmt.Sprintf(".%s", merchant.LocationSymbol)
+	_, marketErr := schema.Market_get_from_db(wdb, market_path)
+	if marketErr != nil {
+		log.Debug.Printf("Could not get market %s from db: %v", market_path, marketErr)
+		responses.SendRes(w, responses.No_Such_Market, nil, merchant.LocationSymbol)
+		return
+	}
+	resource := schema.Resource{}
+	resource.Symbol = reqBody.ResourceSymbol
+	if action == "buy" {
+		resource_path := fmt.Sprintf(".%s", reqBody.ResourceSymbol)
+		var resourceErr error
+		resource, resourceErr = schema.Resource_get_from_db(wdb, resource_path)
+		if resourceErr != nil {
+			log.Debug.Printf("Could not get resource %s from db: %v", resource_path, resourceErr)
+			responses.SendRes(w, responses.Resource_Not_Traded, nil, reqBody.ResourceSymbol)
+			return
+		}
+	}
+	// Drift and trade commit to the market together, so the user is only saved once the new stock level is persisted
+	transaction, tradeErr := gamelogic.TradeAtMarket(wdb, &userData, merchant.Symbol, merchant.LocationSymbol, action, resource, reqBody.Quantity, gamelogic.GameClock.Now().Unix())
+	if tradeErr != nil {
+		log.Debug.Printf("Merchant %s could not %s: %v", merchant.Symbol, action, tradeErr)
+		sendGameErrorRes(w, tradeErr)
+		return
+	}
+	savedToDb := GetUDBAndSaveUserToDB(w, r, userData)
+	if !savedToDb {
+		// The market already moved, put its stock back as the user never got their side of the trade
+		revertErr := gamelogic.RevertMarketTrade(wdb, transaction)
+		if revertErr != nil {
+			log.Error.Printf("Could not revert %s of %d %s at %s by %s after the user save failed: %v", action, transaction.Quantity, transaction.ResourceSymbol, transaction.LocaleSymbol, userData.Username, revertErr)
+		}
+		return // Fail state, handled by func, return
+	}
+	responses.SendRes(w, responses.Generic_Success, transaction, "")
+}
+
+// Move resources between the specified golem's cargo and the locale inventory using transfer
+func transferGolemCargo(w http.ResponseWriter, r *http.Request, transfer func(*schema.User, int, map[string]int) error) {
+	route_vars := mux.Vars(r)
+	symbol := route_vars["symbol"]
+	OK, userData, _, _ := secureGetUser(w, r)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	// Find golem with symbol
+	found, golemIndex := schema.FindIndexOfGolemWithSymbol(userData.Golems, symbol)
+	if !found {
+		// Not Found
+		responses.SendRes(w, responses.No_Golem_Found, nil, "")
+		return
+	}
+	targetGolem := &userData.Golems[golemIndex]
+	// Golems in blocking status are busy, e.g. traveling golems are not at a locale
+	if statusInfo, ok := schema.GolemStatuses[targetGolem.Status]; !ok || statusInfo.IsBlocking {
+		responses.SendRes(w, responses.Golem_In_Blocking_Status, nil, targetGolem.Status)
+		return
+	}
+	gotReqBody, reqBody := getRequestBodyForGolemCargoTransfer(w, r)
+	if !gotReqBody {
+		return // Fail state, handled by func, return
+	}
+	transferErr := transfer(&userData, golemIndex, reqBody.Resources)
+	if transferErr != nil {
+		log.Debug.Printf("Could not transfer cargo for golem %s: %v", symbol, transferErr)
+		sendGameErrorRes(w, transferErr)
+		return
+	}
+	savedToDb := GetUDBAndSaveUserToDB(w, r, userData)
+	if !savedToDb {
+		return // Fail state, handled by func, return
+	}
+	responses.SendRes(w, responses.Generic_Success, userData.Golems[golemIndex], "")
+}
+
+// Get instructions for statusUpdate requests as a map with string keys
+func getStatusInstructions(w http.ResponseWriter, reqBody schema.GolemStatusUpdateBody) (bool, map[string]interface{}) {
+	statusInstructions, ok := reqBody.Instructions.(map[string]interface{})
+	if !ok {
+		// Fail case, instructions missing or not an object
+		responses.SendRes(w, responses.Bad_Request, nil, fmt.Sprintf("instructions object required for '%s' status", reqBody.NewStatus))
+		return false, nil
+	}
+	return true, statusInstructions
 }
 
 func GetUDBAndSaveUserToDB(w http.ResponseWriter, r *http.Request, userData schema.User) (bool) {
@@ -365,12 +552,16 @@ func AccountInfo(w http.ResponseWriter, r *http.Request) {
 	if !OK {
 		return // Failure states handled by secureGetUser, simply return
 	}
-	getUserJsonString, getUserJsonStringErr := responses.JSON(userData)
+	responseData := schema.AccountInfoResponse{
+		User: userData,
+		ManaBreakdown: gamelogic.GetManaBreakdown(userData, gamelogic.GameClock.Now().Unix()),
+	}
+	getUserJsonString, getUserJsonStringErr := responses.JSON(responseData)
 	if getUserJsonStringErr != nil {
 		log.Error.Printf("Error in AccountInfo, could not format thisUser as JSON. userData: %v, error: %v", userData, getUserJsonStringErr)
 	}
 	log.Debug.Printf("Sending response for AccountInfo:\n%v", getUserJsonString)
-	responses.SendRes(w, responses.Generic_Success, userData, "")
+	responses.SendRes(w, responses.Generic_Success, responseData, "")
 	log.Debug.Println(log.Cyan("-- End accountInfo --"))
 }
 
@@ -426,6 +617,152 @@ func GolemInfo(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Cyan("-- End InvokerInfo --"))
 }
 
+// Handler function for the secure route: DELETE /api/v0/my/golem/{symbol}
+func DismissGolem(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- DismissGolem --"))
+	route_vars := mux.Vars(r)
+	symbol := route_vars["symbol"]
+	OK, userData, _, _ := secureGetUser(w, r)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	// Find golem with symbol
+	found, golemIndex := schema.FindIndexOfGolemWithSymbol(userData.Golems, symbol)
+	if !found {
+		// Not Found
+		responses.SendRes(w, responses.No_Golem_Found, nil, "")
+		return
+	}
+	dismissal, dismissErr := gamelogic.DismissGolem(&userData, golemIndex)
+	if dismissErr != nil {
+		log.Debug.Printf("Could not dismiss golem %s: %v", symbol, dismissErr)
+		sendGameErrorRes(w, dismissErr)
+		return
+	}
+	savedToDb := GetUDBAndSaveUserToDB(w, r, userData)
+	if !savedToDb {
+		return // Fail state, handled by func, return
+	}
+	log.Debug.Printf("Dismissed golem %s for username %s", dismissal.Golem.Symbol, userData.Username)
+	detail := ""
+	if dismissal.ManaForfeited > 0 {
+		detail = fmt.Sprintf("mana cap reached, %v of the %v refund was forfeited", dismissal.ManaForfeited, dismissal.ManaRefundDue)
+	}
+	responses.SendRes(w, responses.Generic_Success, dismissal, detail)
+	log.Debug.Println(log.Cyan("-- End DismissGolem --"))
+}
+
+// Handler function for the secure route: GET /api/v0/my/inventory
+func GetInventories(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- GetInventories --"))
+	OK, userData, _, _ := secureGetUser(w, r)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	responses.SendRes(w, responses.Generic_Success, userData.Inventory, "")
+	log.Debug.Println(log.Cyan("-- End GetInventories --"))
+}
+
+// Handler function for the secure route: GET /api/v0/my/inventory/{locale}
+func GetInventoryByLocale(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- GetInventoryByLocale --"))
+	route_vars := mux.Vars(r)
+	locale := route_vars["locale"]
+	OK, userData, _, _ := secureGetUser(w, r)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	wdbSuccess, wdb := GetWdbFromCtx(w, r)
+	if !wdbSuccess {
+		return // Fail state, could not get wdb, handled by func - simply return
+	}
+	// Ensure locale exists so typos are not reported as empty inventories
+	locale_path := fmt.Sprintf(".%s", locale)
+	cur_locale, localeErr := schema.Locale_get_from_db(wdb, locale_path)
+	if localeErr != nil {
+		log.Debug.Printf("Could not get locale %s from db: %v", locale_path, localeErr)
+		responses.SendRes(w, responses.No_Such_Locale, nil, locale)
+		return
+	}
+	inventory, ok := userData.Inventory[cur_locale.Symbol]
+	if !ok {
+		inventory = schema.NewLocationInventory(cur_locale.Symbol)
+	}
+	responses.SendRes(w, responses.Generic_Success, inventory, "")
+	log.Debug.Println(log.Cyan("-- End GetInventoryByLocale --"))
+}
+
+// Handler function for the secure route: GET /api/v0/my/merchants
+func GetVisibleMarkets(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- GetVisibleMarkets --"))
+	OK, userData, _, _ := secureGetUser(w, r)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	wdbSuccess, wdb := GetWdbFromCtx(w, r)
+	if !wdbSuccess {
+		return // Fail state, could not get wdb, handled by func - simply return
+	}
+	markets, marketsErr := schema.Market_get_all_from_db(wdb)
+	if marketsErr != nil {
+		log.Error.Printf("Could not get markets from DB! Err: %v", marketsErr)
+		responses.SendRes(w, responses.WDB_Get_Failure, nil, "could not get markets")
+		return
+	}
+	// Fog of war, only show markets where a merchant is present
+	visibleMarkets := make(map[string]schema.Market)
+	for localeSymbol := range getMerchantLocales(userData) {
+		if market, ok := markets[localeSymbol]; ok {
+			visibleMarkets[localeSymbol] = gamelogic.CalculateMarketDrift(market, gamelogic.GameClock.Now().Unix())
+		}
+	}
+	responses.SendRes(w, responses.Generic_Success, visibleMarkets, "")
+	log.Debug.Println(log.Cyan("-- End GetVisibleMarkets --"))
+}
+
+// Handler function for the secure route: GET /api/v0/my/merchants/{locale}
+func GetMarketByLocale(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- GetMarketByLocale --"))
+	route_vars := mux.Vars(r)
+	locale := route_vars["locale"]
+	OK, userData, _, _ := secureGetUser(w, r)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	wdbSuccess, wdb := GetWdbFromCtx(w, r)
+	if !wdbSuccess {
+		return // Fail state, could not get wdb, handled by func - simply return
+	}
+	// Fog of war, only show market if a merchant is present
+	if !getMerchantLocales(userData)[locale] {
+		responses.SendRes(w, responses.No_Merchant_Present, nil, locale)
+		return
+	}
+	market_path := fmt.Sprintf(".%s", locale)
+	market, marketErr := schema.Market_get_from_db(wdb, market_path)
+	if marketErr != nil {
+		log.Debug.Printf("Could not get market %s from db: %v", market_path, marketErr)
+		responses.SendRes(w, responses.No_Such_Market, nil, locale)
+		return
+	}
+	responses.SendRes(w, responses.Generic_Success, gamelogic.CalculateMarketDrift(market, gamelogic.GameClock.Now().Unix()), "")
+	log.Debug.Println(log.Cyan("-- End GetMarketByLocale --"))
+}
+
+// Handler function for the secure route: POST /api/v0/my/merchants/{symbol}/buy
+func MerchantBuy(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- MerchantBuy --"))
+	merchantTrade(w, r, "buy")
+	log.Debug.Println(log.Cyan("-- End MerchantBuy --"))
+}
+
+// Handler function for the secure route: POST /api/v0/my/merchants/{symbol}/sell
+func MerchantSell(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- MerchantSell --"))
+	merchantTrade(w, r, "sell")
+	log.Debug.Println(log.Cyan("-- End MerchantSell --"))
+}
+
 // Handler function for the secure route: GET /api/v0/my/rituals
 func ListRituals(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Yellow("-- ListRituals --"))
@@ -435,7 +772,12 @@ func ListRituals(w http.ResponseWriter, r *http.Request) {
 	}
 	var responseData []schema.Ritual
 	for _, ritual := range userData.KnownRituals {
-		responseData = append(responseData, schema.Rituals[ritual])
+		ritualInfo, ok := schema.Rituals[ritual]
+		if !ok {
+			// Rituals removed from json since the user learned them are skipped
+			continue
+		}
+		responseData = append(responseData, ritualInfo)
 	}
 	responses.SendRes(w, responses.Generic_Success, responseData, "")
 	log.Debug.Println(log.Cyan("-- End ListRituals --"))
@@ -455,8 +797,9 @@ func GetRitualInfo(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		// Fail case - no ritual found
 		responses.SendRes(w, responses.No_Such_Ritual, nil, "")
+		return
 	}
-	knowsRitual := doesUserKnowRitual(userData, ritual)
+	knowsRitual := schema.DoesUserKnowRitual(userData, ritual)
 	if !knowsRitual {
 		responses.SendRes(w, responses.Ritual_Not_Known, nil, "")
 		return
@@ -466,32 +809,169 @@ func GetRitualInfo(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Cyan("-- End GetRitualInfo --"))
 }
 
-// Handler function for the secure route: POST /api/v0/my/rituals/summon-invoker
-func NewInvoker(w http.ResponseWriter, r *http.Request) {
-	log.Debug.Println(log.Yellow("-- NewInvoker --"))
-	OK, userData, udb, _ := secureGetUser(w, r)
+// Handler function for the secure route: POST /api/v0/my/rituals/{ritual}
+func ExecuteRitual(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- ExecuteRitual --"))
+	// Get ritual from route
+	route_vars := mux.Vars(r)
+	ritualSymbol := route_vars["ritual"]
+	OK, userData, _, _ := secureGetUser(w, r)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	ritual, ok := schema.Rituals[ritualSymbol]
+	if !ok {
+		// Fail case - no ritual found
+		responses.SendRes(w, responses.No_Such_Ritual, nil, "")
+		return
+	}
+	if !schema.DoesUserKnowRitual(userData, ritualSymbol) {
+		responses.SendRes(w, responses.Ritual_Not_Known, nil, "")
+		return
+	}
+	gotReqBody, reqBody := getRequestBodyForRitual(w, r, ritual)
+	if !gotReqBody {
+		return // Fail state, handled by func, return
+	}
+	golemIndex := -1
+	localeSymbol := schema.Starting_Locale_Symbol
+	if schema.RitualTargetsGolem(ritual) {
+		// Find golem with symbol
+		found, foundIndex := schema.FindIndexOfGolemWithSymbol(userData.Golems, reqBody.GolemSymbol)
+		if !found {
+			// Not Found
+			responses.SendRes(w, responses.No_Golem_Found, nil, "")
+			return
+		}
+		golemIndex = foundIndex
+		// A traveling golem is at no locale, so there is nowhere to cast the ritual
+		if strings.EqualFold(userData.Golems[golemIndex].LocationSymbol, schema.In_Transit_Location_Symbol) {
+			sendGameErrorRes(w, fmt.Errorf("%w: %s cannot be the target of %s", schema.ErrGolemInTransit, reqBody.GolemSymbol, ritualSymbol))
+			return
+		}
+		localeSymbol = userData.Golems[golemIndex].LocationSymbol
+	}
+	wdbSuccess, wdb := GetWdbFromCtx(w, r)
+	if !wdbSuccess {
+		return // Fail state, could not get wdb, handled by func - simply return
+	}
+	if reqBody.LocaleSymbol != "" {
+		// Ensure locale exists, golems and circles must never be placed somewhere that is not in the world
+		locale, localeErr := schema.Locale_get_from_db(wdb, fmt.Sprintf(".%s", reqBody.LocaleSymbol))
+		if localeErr != nil {
+			log.Debug.Printf("Could not get locale %s from db: %v", reqBody.LocaleSymbol, localeErr)
+			responses.SendRes(w, responses.No_Such_Locale, nil, reqBody.LocaleSymbol)
+			return
+		}
+		localeSymbol = locale.Symbol
+	}
+	// Every repetition is applied to userData before the single save, nothing is saved if any fails
+	target := schema.RitualTarget{LocaleSymbol: localeSymbol, GolemIndex: golemIndex, DestinationSymbol: reqBody.DestinationSymbol}
+	result, ritualErr := gamelogic.PerformRitualRepeatedly(&userData, ritual, target, reqBody.Repetitions, wdb, gamelogic.GameClock.Now().Unix())
+	if ritualErr != nil {
+		log.Debug.Printf("Could not perform ritual %s: %v", ritualSymbol, ritualErr)
+		sendGameErrorRes(w, ritualErr)
+		return
+	}
+	savedToDb := GetUDBAndSaveUserToDB(w, r, userData)
+	if !savedToDb {
+		return // Fail state, handled by func, return
+	}
+	log.Debug.Printf("Performed ritual %s x%d for username %s", ritualSymbol, reqBody.Repetitions, userData.Username)
+	responses.SendRes(w, responses.Generic_Success, result, "")
+	log.Debug.Println(log.Cyan("-- End ExecuteRitual --"))
+}
+
+// Handler function for the secure route: GET /api/v0/my/research
+func GetResearch(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- GetResearch --"))
+	OK, userData, _, _ := secureGetUser(w, r)
 	if !OK {
 		return // Failure states handled by secureGetUser, simply return
 	}
-	success := createNewGolemInDB(w, r, udb, userData, "invoker", "summon-invoker", "invoking", gamelogic.Capacity_Invoker)
-	if !success {
-		return // Failure states handled by createNewGolemInDB, simply return
+	responseData := gamelogic.GetResearchStatuses(userData, gamelogic.GameClock.Now().Unix())
+	responses.SendRes(w, responses.Generic_Success, responseData, "")
+	log.Debug.Println(log.Cyan("-- End GetResearch --"))
+}
+
+// Handler function for the secure route: POST /api/v0/my/research/{research}
+func StartResearch(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- StartResearch --"))
+	// Get research from route
+	route_vars := mux.Vars(r)
+	researchSymbol := route_vars["research"]
+	OK, userData, _, _ := secureGetUser(w, r)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	research, ok := schema.ResearchTree[researchSymbol]
+	if !ok {
+		// Fail case - no research found
+		responses.SendRes(w, responses.No_Such_Research, nil, researchSymbol)
+		return
 	}
-	log.Debug.Println(log.Cyan("-- End NewInvoker --"))
+	gotReqBody, reqBody := getRequestBodyForPurchase(w, r)
+	if !gotReqBody {
+		return // Fail state, handled by func, return
+	}
+	startErr := gamelogic.StartResearch(&userData, research, reqBody.LocaleSymbol, gamelogic.GameClock.Now().Unix())
+	if startErr != nil {
+		log.Debug.Printf("Could not start research %s: %v", researchSymbol, startErr)
+		sendGameErrorRes(w, startErr)
+		return
+	}
+	savedToDb := GetUDBAndSaveUserToDB(w, r, userData)
+	if !savedToDb {
+		return // Fail state, handled by func, return
+	}
+	responses.SendRes(w, responses.Generic_Success, userData.ActiveResearch, "")
+	log.Debug.Println(log.Cyan("-- End StartResearch --"))
+}
+
+// Handler function for the secure route: GET /api/v0/my/mana-upgrades
+func GetManaUpgrades(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- GetManaUpgrades --"))
+	OK, userData, _, _ := secureGetUser(w, r)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	responses.SendRes(w, responses.Generic_Success, gamelogic.GetManaUpgradeStatuses(userData), "")
+	log.Debug.Println(log.Cyan("-- End GetManaUpgrades --"))
 }
 
-// Handler function for the secure route: POST /api/v0/my/rituals/summon-harvester
-func NewHarvester(w http.ResponseWriter, r *http.Request) {
-	log.Debug.Println(log.Yellow("-- NewHarvester --"))
-	OK, userData, udb, _ := secureGetUser(w, r)
+// Handler function for the secure route: POST /api/v0/my/mana-upgrades/{upgrade}
+func BuyManaUpgrade(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- BuyManaUpgrade --"))
+	// Get upgrade from route
+	route_vars := mux.Vars(r)
+	upgradeSymbol := route_vars["upgrade"]
+	OK, userData, _, _ := secureGetUser(w, r)
 	if !OK {
 		return // Failure states handled by secureGetUser, simply return
 	}
-	success := createNewGolemInDB(w, r, udb, userData, "harvester", "summon-harvester", "idle", gamelogic.Capacity_Harvester)
-	if !success {
-		return // Failure states handled by createNewGolemInDB, simply return
+	upgrade, ok := schema.ManaUpgrades[upgradeSymbol]
+	if !ok {
+		// Fail case - no upgrade found
+		responses.SendRes(w, responses.No_Such_Mana_Upgrade, nil, upgradeSymbol)
+		return
+	}
+	gotReqBody, reqBody := getRequestBodyForPurchase(w, r)
+	if !gotReqBody {
+		return // Fail state, handled by func, return
+	}
+	// Mana is already regenerated up to now by secureGetUser, so the new cap and regen only count from here
+	_, buyErr := gamelogic.BuyManaUpgrade(&userData, upgrade, reqBody.LocaleSymbol)
+	if buyErr != nil {
+		log.Debug.Printf("Could not buy mana upgrade %s: %v", upgradeSymbol, buyErr)
+		sendGameErrorRes(w, buyErr)
+		return
+	}
+	savedToDb := GetUDBAndSaveUserToDB(w, r, userData)
+	if !savedToDb {
+		return // Fail state, handled by func, return
 	}
-	log.Debug.Println(log.Cyan("-- End NewHarvester --"))
+	responses.SendRes(w, responses.Generic_Success, gamelogic.GetManaUpgradeStatus(userData, upgrade), "")
+	log.Debug.Println(log.Cyan("-- End BuyManaUpgrade --"))
 }
 
 // Handler function for the secure route: PUT /api/v0/my/invokers/{symbol}
@@ -510,9 +990,8 @@ func ChangeGolemTask(w http.ResponseWriter, r *http.Request) {
 		responses.SendRes(w, responses.No_Golem_Found, nil, "")
 		return
 	}
-	targetGolem := &userData.Golems[golemIndex]
-	currentStatus := targetGolem.Status
-	archetype := targetGolem.Archetype
+	currentStatus := userData.Golems[golemIndex].Status
+	archetype := userData.Golems[golemIndex].Archetype
 
 	// Check golem for blocking status, verify new status is allowed based on archetype
 	changeAllowed, reqBody := checkStatusChangeAllowedAndGetReqBody(w, r, currentStatus, archetype)
@@ -520,6 +999,89 @@ func ChangeGolemTask(w http.ResponseWriter, r *http.Request) {
 		return // Fail state, handled by func, return
 	}
 	// Success state, new status is allowed, complete changes based on request body
-	executeGolemStatusChange(w, r, reqBody, &userData, targetGolem)
+	executeGolemStatusChange(w, r, reqBody, &userData, golemIndex)
 	log.Debug.Println(log.Cyan("-- End ChangeGolemTask --"))
-}
\ No newline at end of file
+}
+
+// Handler function for the secure route: POST /api/v0/my/golem/{symbol}/load
+func LoadGolem(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- LoadGolem --"))
+	transferGolemCargo(w, r, gamelogic.LoadGolemCargo)
+	log.Debug.Println(log.Cyan("-- End LoadGolem --"))
+}
+
+// Handler function for the secure route: POST /api/v0/my/golem/{symbol}/unload
+func UnloadGolem(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- UnloadGolem --"))
+	transferGolemCargo(w, r, gamelogic.UnloadGolemCargo)
+	log.Debug.Println(log.Cyan("-- End UnloadGolem --"))
+}
+
+// Handler function for the secure route: PUT /api/v0/my/golem/{symbol}/orders
+func SetGolemOrders(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- SetGolemOrders --"))
+	route_vars := mux.Vars(r)
+	symbol := route_vars["symbol"]
+	OK, userData, _, _ := secureGetUser(w, r)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	// Find golem with symbol
+	found, golemIndex := schema.FindIndexOfGolemWithSymbol(userData.Golems, symbol)
+	if !found {
+		// Not Found
+		responses.SendRes(w, responses.No_Golem_Found, nil, "")
+		return
+	}
+	// The first order starts right away, so the golem must be free to take it
+	if statusInfo, ok := schema.GolemStatuses[userData.Golems[golemIndex].Status]; !ok || statusInfo.IsBlocking {
+		responses.SendRes(w, responses.Golem_In_Blocking_Status, nil, userData.Golems[golemIndex].Status)
+		return
+	}
+	gotReqBody, reqBody := getRequestBodyForGolemOrders(w, r)
+	if !gotReqBody {
+		return // Fail state, handled by func, return
+	}
+	wdbSuccess, wdb := GetWdbFromCtx(w, r)
+	if !wdbSuccess {
+		return // Fail state, could not get wdb, handled by func - simply return
+	}
+	ordersErr := gamelogic.SetGolemOrders(&userData, golemIndex, reqBody.Orders, reqBody.Repeat, wdb, gamelogic.GameClock.Now())
+	if ordersErr != nil {
+		log.Debug.Printf("Could not set orders for golem %s: %v", symbol, ordersErr)
+		sendGameErrorRes(w, ordersErr)
+		return
+	}
+	savedToDb := GetUDBAndSaveUserToDB(w, r, userData)
+	if !savedToDb {
+		return // Fail state, handled by func, return
+	}
+	responses.SendRes(w, responses.Generic_Success, userData.Golems[golemIndex], "")
+	log.Debug.Println(log.Cyan("-- End SetGolemOrders --"))
+}
+
+// Handler function for the secure route: DELETE /api/v0/my/golem/{symbol}/orders
+func ClearGolemOrders(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- ClearGolemOrders --"))
+	route_vars := mux.Vars(r)
+	symbol := route_vars["symbol"]
+	OK, userData, _, _ := secureGetUser(w, r)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	// Find golem with symbol
+	found, golemIndex := schema.FindIndexOfGolemWithSymbol(userData.Golems, symbol)
+	if !found {
+		// Not Found
+		responses.SendRes(w, responses.No_Golem_Found, nil, "")
+		return
+	}
+	// The current order is left to finish, only the rest of the queue is dropped
+	gamelogic.ClearGolemOrders(&userData.Golems[golemIndex])
+	savedToDb := GetUDBAndSaveUserToDB(w, r, userData)
+	if !savedToDb {
+		return // Fail state, handled by func, return
+	}
+	responses.SendRes(w, responses.Generic_Success, userData.Golems[golemIndex], "")
+	log.Debug.Println(log.Cyan("-- End ClearGolemOrders --"))
+}
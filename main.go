@@ -1,13 +1,21 @@
 package main
 
 import (
+	"context"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/brct-james/guild-golems/auth"
 	"github.com/brct-james/guild-golems/filemngr"
+	"github.com/brct-james/guild-golems/gamelogic"
 	"github.com/brct-james/guild-golems/handlers"
 	"github.com/brct-james/guild-golems/log"
+	"github.com/brct-james/guild-golems/metrics"
 	"github.com/brct-james/guild-golems/rdb"
+	"github.com/brct-james/guild-golems/scheduler"
 	"github.com/brct-james/guild-golems/schema"
 	"github.com/gorilla/mux"
 )
@@ -24,10 +32,24 @@ var localeJSONPath string = "./static-files/json/v0_locales.json"
 var resourceJSONPath string = "./static-files/json/v0_resources.json"
 var resourceNodeJSONPath string = "./static-files/json/v0_resource_nodes.json"
 var routeJSONPath string = "./static-files/json/v0_routes.json"
+var marketJSONPath string = "./static-files/json/v0_markets.json"
+var recipeJSONPath string = "./static-files/json/v0_recipes.json"
+var blueprintJSONPath string = "./static-files/json/v0_blueprints.json"
+var ritualJSONPath string = "./static-files/json/v0_rituals.json"
+var researchJSONPath string = "./static-files/json/v0_research.json"
+var manaUpgradeJSONPath string = "./static-files/json/v0_mana_upgrades.json"
 
 // Game Configuration
 // in user-metrics.go: activityThresholdInMinutes controls what users are considered 'active'
 
+// Background job intervals
+var nodeReplenishInterval time.Duration = 1 * time.Minute
+var marketDriftInterval time.Duration = 1 * time.Minute
+var leaderboardInterval time.Duration = 5 * time.Minute
+var inactiveUserCleanupInterval time.Duration = 15 * time.Minute
+// How long in-flight requests get to finish on shutdown
+var shutdownTimeout time.Duration = 10 * time.Second
+
 // Define relationship between string database name and redis db num
 var dbMap = map[string]int{
 	"users": 0,
@@ -44,6 +66,7 @@ var (
 
 var userDatabase rdb.Database
 var worldDatabase rdb.Database
+var worldScheduler *scheduler.Scheduler
 
 // Main
 func main() {
@@ -53,6 +76,12 @@ func main() {
 	userDatabase = rdb.NewDatabase(RedisAddr, dbMap["users"])
 	worldDatabase = rdb.NewDatabase(RedisAddr, dbMap["world"])
 
+	// Before the world, as region entry requirements are checked against rituals
+	log.Info.Println("Loading rituals.json, research.json and mana_upgrades.json")
+	loadRituals()
+	loadResearch()
+	loadManaUpgrades()
+
 	if reloadWorldFromJSON {
 		log.Important.Printf("Flushing World Database")
 		worldDatabase.Flush()
@@ -73,11 +102,89 @@ func main() {
 	log.Info.Println("Loading secrets from envfile")
 	auth.LoadSecretsToEnv()
 
+	log.Info.Println("Starting background jobs")
+	worldScheduler = scheduler.New()
+	registerScheduledJobs(worldScheduler)
+	worldScheduler.Start()
+
 	// Begin serving
 	handle_requests()
 }
 
-// Load world file from json and save it to world database
+// Register the jobs that evolve shared world state while users are away
+func registerScheduledJobs(s *scheduler.Scheduler) {
+	jobs := []scheduler.Job{
+		{Name: "node-replenishment", Interval: nodeReplenishInterval, Run: func() error {
+			resourceNodes, nodesErr := schema.ResourceNode_get_all_from_db(worldDatabase)
+			if nodesErr != nil {
+				return nodesErr
+			}
+			return gamelogic.ReplenishAllNodes(worldDatabase, resourceNodes, gamelogic.GameClock.Now().Unix())
+		}},
+		{Name: "market-drift", Interval: marketDriftInterval, Run: func() error {
+			return gamelogic.DriftAllMarkets(worldDatabase, gamelogic.GameClock.Now().Unix())
+		}},
+		{Name: "leaderboards", Interval: leaderboardInterval, Run: func() error {
+			return metrics.GenerateLeaderboards(userDatabase)
+		}},
+		{Name: "inactive-user-cleanup", Interval: inactiveUserCleanupInterval, Run: func() error {
+			pruned := metrics.PruneInactiveUsers(gamelogic.GameClock.Now())
+			log.Debug.Printf("Pruned %d inactive users from activity metrics", pruned)
+			return nil
+		}},
+	}
+	for _, job := range jobs {
+		registerErr := s.Register(job)
+		if registerErr != nil {
+			// Fail state, crash as a misconfigured job would silently never run
+			log.Error.Fatalf("Could not register scheduled job: %v", registerErr)
+		}
+	}
+}
+
+// Load ritual definitions from json into memory, rituals are static so they are not kept in the world database
+func loadRituals() {
+	rituals, ritual_json_err := schema.Ritual_unmarshal_all_json(filemngr.ReadJSON(ritualJSONPath))
+	if ritual_json_err != nil {
+		log.Error.Fatalf("Could not unmarshal ritual json: %v", ritual_json_err)
+	}
+	ritual_validate_err := schema.ValidateRituals(rituals)
+	if ritual_validate_err != nil {
+		// Fail state, crash as a broken ritual would fail for users at runtime
+		log.Error.Fatalf("Invalid ritual json: %v", ritual_validate_err)
+	}
+	schema.Rituals = rituals
+}
+
+// Load the research tree from json into memory, must be called after loadRituals as research teaches rituals
+func loadResearch() {
+	research, research_json_err := schema.Research_unmarshal_all_json(filemngr.ReadJSON(researchJSONPath))
+	if research_json_err != nil {
+		log.Error.Fatalf("Could not unmarshal research json: %v", research_json_err)
+	}
+	research_validate_err := schema.ValidateResearch(research, schema.Rituals)
+	if research_validate_err != nil {
+		// Fail state, crash as broken research would fail for users at runtime
+		log.Error.Fatalf("Invalid research json: %v", research_validate_err)
+	}
+	schema.ResearchTree = research
+}
+
+// Load mana upgrade definitions from json into memory
+func loadManaUpgrades() {
+	upgrades, upgrade_json_err := schema.ManaUpgrade_unmarshal_all_json(filemngr.ReadJSON(manaUpgradeJSONPath))
+	if upgrade_json_err != nil {
+		log.Error.Fatalf("Could not unmarshal mana upgrade json: %v", upgrade_json_err)
+	}
+	upgrade_validate_err := schema.ValidateManaUpgrades(upgrades)
+	if upgrade_validate_err != nil {
+		// Fail state, crash as a broken upgrade would fail for users at runtime
+		log.Error.Fatalf("Invalid mana upgrade json: %v", upgrade_validate_err)
+	}
+	schema.ManaUpgrades = upgrades
+}
+
+// Load world file from json and save it to world database, must be called after loadRituals as regions may require rituals
 func initializeWorldDB(wdb rdb.Database) {
 	// --World--
 	world, world_json_err := schema.World_unmarshal_json(filemngr.ReadJSON(worldJSONPath))
@@ -96,6 +203,11 @@ func initializeWorldDB(wdb rdb.Database) {
 	if region_json_err != nil {
 		log.Error.Fatalf("Could not unmarshal region json: %v", region_json_err)
 	}
+	region_requirements_err := schema.ValidateRegionEntryRequirements(regions, schema.Rituals)
+	if region_requirements_err != nil {
+		// Fail state, crash as an unknown title or ritual could never be met
+		log.Error.Fatalf("Invalid region json: %v", region_requirements_err)
+	}
 	region_save_err := schema.Region_save_all_to_db(wdb, regions)
 	if region_save_err != nil {
 		// Fail state, crash as region required
@@ -120,6 +232,11 @@ func initializeWorldDB(wdb rdb.Database) {
 	if route_json_err != nil {
 		log.Error.Fatalf("Could not unmarshal route json: %v", route_json_err)
 	}
+	route_border_err := schema.ValidateRouteBorders(routes, locales, regions)
+	if route_border_err != nil {
+		// Fail state, crash as routes must only cross region borders
+		log.Error.Fatalf("Invalid route json: %v", route_border_err)
+	}
 	route_save_err := schema.Route_save_all_to_db(wdb, routes)
 	if route_save_err != nil {
 		// Fail state, crash as route required
@@ -150,6 +267,51 @@ func initializeWorldDB(wdb rdb.Database) {
 		log.Error.Fatalf("Failed saving resourcenode during wdb init, err: %v", resourceNode_save_err)
 	}
 	schema.Test_resourcenode_initialized(wdb, resourceNodes)
+
+	// --Resource Node States--
+	resourceNodeStates := schema.NewResourceNodeStates(resourceNodes, gamelogic.GameClock.Now().Unix())
+	resourceNodeState_save_err := schema.ResourceNodeState_save_all_to_db(wdb, resourceNodeStates)
+	if resourceNodeState_save_err != nil {
+		// Fail state, crash as resourcenode state required
+		log.Error.Fatalf("Failed saving resourcenode state during wdb init, err: %v", resourceNodeState_save_err)
+	}
+	schema.Test_resourcenodestate_initialized(wdb, resourceNodeStates)
+
+	// --Markets--
+	markets, market_json_err := schema.Market_unmarshal_all_json(filemngr.ReadJSON(marketJSONPath))
+	if market_json_err != nil {
+		log.Error.Fatalf("Could not unmarshal market json: %v", market_json_err)
+	}
+	market_save_err := schema.Market_save_all_to_db(wdb, markets)
+	if market_save_err != nil {
+		// Fail state, crash as market required
+		log.Error.Fatalf("Failed saving market during wdb init, err: %v", market_save_err)
+	}
+	schema.Test_market_initialized(wdb, markets)
+
+	// --Recipes--
+	recipes, recipe_json_err := schema.Recipe_unmarshal_all_json(filemngr.ReadJSON(recipeJSONPath))
+	if recipe_json_err != nil {
+		log.Error.Fatalf("Could not unmarshal recipe json: %v", recipe_json_err)
+	}
+	recipe_save_err := schema.Recipe_save_all_to_db(wdb, recipes)
+	if recipe_save_err != nil {
+		// Fail state, crash as recipe required
+		log.Error.Fatalf("Failed saving recipe during wdb init, err: %v", recipe_save_err)
+	}
+	schema.Test_recipe_initialized(wdb, recipes)
+
+	// --Blueprints--
+	blueprints, blueprint_json_err := schema.Blueprint_unmarshal_all_json(filemngr.ReadJSON(blueprintJSONPath))
+	if blueprint_json_err != nil {
+		log.Error.Fatalf("Could not unmarshal blueprint json: %v", blueprint_json_err)
+	}
+	blueprint_save_err := schema.Blueprint_save_all_to_db(wdb, blueprints)
+	if blueprint_save_err != nil {
+		// Fail state, crash as blueprint required
+		log.Error.Fatalf("Failed saving blueprint during wdb init, err: %v", blueprint_save_err)
+	}
+	schema.Test_blueprint_initialized(wdb, blueprints)
 }
 
 func handle_requests() {
@@ -159,27 +321,67 @@ func handle_requests() {
 	mxr.HandleFunc("/", handlers.Homepage).Methods("GET")
 	mxr.HandleFunc("/api", handlers.ApiSelection).Methods("GET")
 	mxr.HandleFunc("/api/v0", handlers.V0Status).Methods("GET")
+	mxr.HandleFunc("/api/v0/scheduler", handlers.GenerateSchedulerStatusHandler(worldScheduler)).Methods("GET")
 	mxr.HandleFunc("/api/v0/leaderboards", handlers.LeaderboardDescriptions).Methods("GET")
 	mxr.HandleFunc("/api/v0/leaderboards/{board}", handlers.GetLeaderboards).Methods("GET")
 	mxr.HandleFunc("/api/v0/users", handlers.UsersSummary).Methods("GET")
 	mxr.HandleFunc("/api/v0/users/{username}", handlers.UsernameInfo).Methods("GET")
 	mxr.HandleFunc("/api/v0/users/{username}/claim", handlers.UsernameClaim).Methods("POST")
-	mxr.HandleFunc("/api/v0/locations", handlers.LocationsOverview).Methods("GET")
+	mxr.HandleFunc("/api/v0/world", handlers.WorldOverview).Methods("GET")
+	mxr.HandleFunc("/api/v0/regions", handlers.RegionsOverview).Methods("GET")
+	mxr.HandleFunc("/api/v0/regions/{symbol}", handlers.RegionInfo).Methods("GET")
+	mxr.HandleFunc("/api/v0/locales/{symbol}", handlers.LocaleInfo).Methods("GET")
+	mxr.HandleFunc("/api/v0/resources", handlers.ResourcesOverview).Methods("GET")
+	mxr.HandleFunc("/api/v0/recipes", handlers.RecipesOverview).Methods("GET")
+	mxr.HandleFunc("/api/v0/blueprints", handlers.BlueprintsOverview).Methods("GET")
+	mxr.HandleFunc("/api/v0/routes/plan", handlers.PlanRoute).Methods("GET")
 
 	// secure subrouter for account-specific routes
 	secure := mxr.PathPrefix("/api/v0/my").Subrouter()
 	secure.Use(auth.GenerateTokenValidationMiddlewareFunc(userDatabase))
+	secure.Use(handlers.GenerateUserLockMiddlewareFunc())
 	secure.HandleFunc("/account", handlers.AccountInfo).Methods("GET")
 	secure.HandleFunc("/golems", handlers.GetGolems).Methods("GET")
 	secure.HandleFunc("/golems/{archetype}", handlers.GetGolemsByArchetype).Methods("GET")
 	secure.HandleFunc("/golem/{symbol}", handlers.GolemInfo).Methods("GET")
 	secure.HandleFunc("/golem/{symbol}", handlers.ChangeGolemTask).Methods("PUT")
+	secure.HandleFunc("/golem/{symbol}", handlers.DismissGolem).Methods("DELETE")
+	secure.HandleFunc("/golem/{symbol}/load", handlers.LoadGolem).Methods("POST")
+	secure.HandleFunc("/golem/{symbol}/unload", handlers.UnloadGolem).Methods("POST")
+	secure.HandleFunc("/golem/{symbol}/orders", handlers.SetGolemOrders).Methods("PUT")
+	secure.HandleFunc("/golem/{symbol}/orders", handlers.ClearGolemOrders).Methods("DELETE")
+	secure.HandleFunc("/inventory", handlers.GetInventories).Methods("GET")
+	secure.HandleFunc("/inventory/{locale}", handlers.GetInventoryByLocale).Methods("GET")
+	secure.HandleFunc("/merchants", handlers.GetVisibleMarkets).Methods("GET")
+	secure.HandleFunc("/merchants/{locale}", handlers.GetMarketByLocale).Methods("GET")
+	secure.HandleFunc("/merchants/{symbol}/buy", handlers.MerchantBuy).Methods("POST")
+	secure.HandleFunc("/merchants/{symbol}/sell", handlers.MerchantSell).Methods("POST")
 	secure.HandleFunc("/rituals", handlers.ListRituals).Methods("GET")
 	secure.HandleFunc("/rituals/{ritual}", handlers.GetRitualInfo).Methods("GET")
-	secure.HandleFunc("/rituals/summon-invoker", handlers.NewInvoker).Methods("POST")
-	secure.HandleFunc("/rituals/summon-harvester", handlers.NewHarvester).Methods("POST")
+	secure.HandleFunc("/rituals/{ritual}", handlers.ExecuteRitual).Methods("POST")
+	secure.HandleFunc("/research", handlers.GetResearch).Methods("GET")
+	secure.HandleFunc("/research/{research}", handlers.StartResearch).Methods("POST")
+	secure.HandleFunc("/mana-upgrades", handlers.GetManaUpgrades).Methods("GET")
+	secure.HandleFunc("/mana-upgrades/{upgrade}", handlers.BuyManaUpgrade).Methods("POST")
 
 	// Start listening
-	log.Info.Printf("Listening on %s", ListenPort)
-	log.Error.Fatal(http.ListenAndServe(ListenPort, mxr))
+	server := &http.Server{Addr: ListenPort, Handler: mxr}
+	go func() {
+		log.Info.Printf("Listening on %s", ListenPort)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error.Fatal(err)
+		}
+	}()
+
+	// Shut down gracefully on interrupt, letting in-flight requests and jobs finish
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+	log.Important.Printf("Shutting down")
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Error.Printf("Server did not shut down cleanly: %v", err)
+	}
+	worldScheduler.Stop()
 }
\ No newline at end of file
@@ -0,0 +1,72 @@
+// Package metrics defines functions for tracking and displaying various game and server metrics
+package metrics
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/brct-james/guild-golems/rdb"
+	"github.com/brct-james/guild-golems/schema"
+)
+
+// How many users each leaderboard ranks
+var LeaderboardSize int = 10
+
+// Rank every user in the udb and replace the contents of each leaderboard
+func GenerateLeaderboards(udb rdb.Database) error {
+	tokens, keysErr := udb.GetKeys("*")
+	if keysErr != nil {
+		return keysErr
+	}
+	users := make([]schema.User, 0, len(tokens))
+	for _, token := range tokens {
+		userData, found, getErr := schema.GetUserFromDB(token, udb)
+		if getErr != nil {
+			return getErr
+		}
+		if found {
+			users = append(users, userData)
+		}
+	}
+	achievementCounts := make(map[string]int)
+	for _, achievement := range CalculateUsersByAchievement() {
+		for _, username := range achievement.Users {
+			achievementCounts[username]++
+		}
+	}
+	schema.SetLeaderboardUsers("coin-leaders", rankUsers(users, func(user schema.User) uint64 {
+		return user.Coins
+	}))
+	schema.SetLeaderboardUsers("golem-leaders", rankUsers(users, func(user schema.User) uint64 {
+		return uint64(len(user.Golems))
+	}))
+	schema.SetLeaderboardUsers("achievement-leaders", rankUsers(users, func(user schema.User) uint64 {
+		return uint64(achievementCounts[user.Username])
+	}))
+	return nil
+}
+
+// Get the top LeaderboardSize users by score, highest first, users scoring 0 are not ranked
+// Ties are broken by username so rankings are stable between runs
+func rankUsers(users []schema.User, score func(schema.User) uint64) ([]schema.LeaderboardEntry) {
+	ranked := make([]schema.User, 0, len(users))
+	for _, user := range users {
+		if score(user) > 0 {
+			ranked = append(ranked, user)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if score(ranked[i]) != score(ranked[j]) {
+			return score(ranked[i]) > score(ranked[j])
+		}
+		return strings.ToLower(ranked[i].Username) < strings.ToLower(ranked[j].Username)
+	})
+	if len(ranked) > LeaderboardSize {
+		ranked = ranked[:LeaderboardSize]
+	}
+	entries := make([]schema.LeaderboardEntry, 0, len(ranked))
+	for i, user := range ranked {
+		entries = append(entries, schema.LeaderboardEntry{Rank: i + 1, PublicUserInfo: user.PublicUserInfo})
+	}
+	return entries
+}
@@ -4,6 +4,7 @@ package metrics
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/brct-james/guild-golems/schema"
@@ -49,11 +50,15 @@ func TrackNewUser(username string) {
 
 // Active Users
 var ActivityThresholdInMinutes int = 60
+// Guards TrackingActiveUsers, which is pruned in the background while handlers track calls
+var activityMutex sync.Mutex
 var TrackingActiveUsers = schema.ActiveUsersMetric {
 	Metric: schema.Metric{Name:"Active Users", Description:fmt.Sprintf("List of every user who is considered active: have registered as a new user or hit a secure endpoint in the last %d minutes.", ActivityThresholdInMinutes)},
 	UserActivity: make([]schema.UserCallTimestamp, 0),
 }
 func CalculateActiveUsers() ([]string) {
+	activityMutex.Lock()
+	defer activityMutex.Unlock()
 	res := make([]string, 0)
 	for _, user := range TrackingActiveUsers.UserActivity {
 		exclusion_time := timecalc.AddMinutesToTimestamp(time.Unix(user.LastCallTimestamp, 0), ActivityThresholdInMinutes)
@@ -65,6 +70,8 @@ func CalculateActiveUsers() ([]string) {
 	return res
 }
 func TrackUserCall(username string) {
+	activityMutex.Lock()
+	defer activityMutex.Unlock()
 	foundUser, userIndex, _ := findActiveUserByName(TrackingActiveUsers.UserActivity, username)
 	if !foundUser {
 		// New User
@@ -75,6 +82,22 @@ func TrackUserCall(username string) {
 	TrackingActiveUsers.UserActivity[userIndex].LastCallTimestamp = time.Now().Unix()
 }
 
+// Drop activity records of users who have not been active since before the threshold, returns how many were dropped
+func PruneInactiveUsers(now time.Time) int {
+	activityMutex.Lock()
+	defer activityMutex.Unlock()
+	kept := make([]schema.UserCallTimestamp, 0, len(TrackingActiveUsers.UserActivity))
+	for _, user := range TrackingActiveUsers.UserActivity {
+		exclusion_time := timecalc.AddMinutesToTimestamp(time.Unix(user.LastCallTimestamp, 0), ActivityThresholdInMinutes)
+		if exclusion_time.After(now) {
+			kept = append(kept, user)
+		}
+	}
+	pruned := len(TrackingActiveUsers.UserActivity) - len(kept)
+	TrackingActiveUsers.UserActivity = kept
+	return pruned
+}
+
 // Users by Achievement
 var TrackingUsersByAchievement = schema.UsersByAchievementMetric {
 	Metric: schema.Metric{Name:"Users By Achievement", Description:"List of all achievements and the users who have achieved them."},
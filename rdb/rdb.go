@@ -2,6 +2,7 @@ package rdb
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -29,6 +30,8 @@ func NewDatabase(redisAddr string, dbNum int) Database {
 type InteractiveDB interface {
 	SetJsonData(key string, path string, data interface{}) (error)
 	GetJsonData(key string, path string) ([]uint8, error)
+	UpdateJsonData(key string, path string, update func([]uint8) (interface{}, error)) (error)
+	GetKeys(pattern string) ([]string, error)
 	Flush() (error)
 }
 
@@ -76,6 +79,69 @@ func (db Database) GetJsonData(key string, path string) ([]uint8, error) {
 	return dataJSON, nil
 }
 
+// Number of times UpdateJsonData retries when another client changes the key mid-update
+var MaxUpdateRetries int = 10
+
+// Atomically read-modify-write json data for key at path.
+// 
+// Uses an optimistic WATCH/MULTI transaction: update receives the current json and returns the data to set,
+// and the whole read-modify-write is retried if another client modifies key in the meantime.
+// If update returns an error nothing is written and the error is passed back.
+func (db Database) UpdateJsonData(key string, path string, update func(current []uint8) (interface{}, error)) error {
+	log.Debug.Printf("New attempt UpdateJsonData")
+	log.Debug.Printf("Key: '%s', Path: '%s'", key, path)
+	ctx := context.Background()
+	txFunc := func(tx *goredis.Tx) error {
+		getCmd := goredis.NewStringCmd(ctx, "JSON.GET", key, path)
+		if getErr := tx.Process(ctx, getCmd); getErr != nil {
+			return getErr
+		}
+		current := getCmd.Val()
+		updated, updateErr := update([]uint8(current))
+		if updateErr != nil {
+			return updateErr
+		}
+		updatedJSON, marshalErr := json.Marshal(updated)
+		if marshalErr != nil {
+			return marshalErr
+		}
+		_, pipeErr := tx.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+			pipe.Do(ctx, "JSON.SET", key, path, string(updatedJSON))
+			return nil
+		})
+		return pipeErr
+	}
+	for attempt := 0; attempt < MaxUpdateRetries; attempt++ {
+		err := db.Goredis.Watch(ctx, txFunc, key)
+		if err == nil {
+			log.Debug.Printf("UpdateJsonData Success")
+			return nil
+		}
+		if !errors.Is(err, goredis.TxFailedErr) {
+			log.Debug.Printf("Failed to UpdateJsonData (key: %s, path: %s), reason: '%v'", key, path, err)
+			return err
+		}
+		// Key changed under us, retry with fresh data
+		log.Debug.Printf("UpdateJsonData conflict on attempt %d (key: %s, path: %s), retrying", attempt, key, path)
+	}
+	return fmt.Errorf("failed to UpdateJsonData (key: %s, path: %s) after %d attempts", key, path, MaxUpdateRetries)
+}
+
+// Get every key matching pattern using Goredis SCAN, so large databases are not blocked like with KEYS
+func (db Database) GetKeys(pattern string) ([]string, error) {
+	ctx := context.Background()
+	keys := make([]string, 0)
+	iter := db.Goredis.Scan(ctx, 0, pattern, 0).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		log.Error.Printf("Failed to scan keys (pattern: %s), error: '%v'", pattern, err)
+		return nil, err
+	}
+	return keys, nil
+}
+
 // Flush database using Goredis
 func (db Database) Flush() error {
 	if err := db.Goredis.FlushDB(context.Background()).Err(); err != nil {
@@ -48,6 +48,25 @@ const (
 	Target_Route_Unavailable ResponseCode = 22
 	UDB_Update_Failed ResponseCode = 23
 	Leaderboard_Not_Found ResponseCode = 24
+	Target_Resource_Node_Unavailable ResponseCode = 25
+	No_Such_Locale ResponseCode = 26
+	Insufficient_Resources ResponseCode = 27
+	Insufficient_Capacity ResponseCode = 28
+	No_Merchant_Present ResponseCode = 29
+	No_Such_Market ResponseCode = 30
+	Resource_Not_Traded ResponseCode = 31
+	Not_Enough_Coins ResponseCode = 32
+	Golem_Wrong_Archetype ResponseCode = 33
+	No_Such_Recipe ResponseCode = 34
+	Requirements_Not_Met ResponseCode = 35
+	No_Such_Blueprint ResponseCode = 36
+	No_Path_Found ResponseCode = 37
+	No_Such_Region ResponseCode = 38
+	Golem_Exhausted ResponseCode = 39
+	Not_Enough_Invokers ResponseCode = 40
+	No_Such_Research ResponseCode = 41
+	No_Such_Mana_Upgrade ResponseCode = 42
+	Golem_Broken ResponseCode = 43
 )
 
 // Defines Response structure for output
@@ -118,6 +137,44 @@ func FormatResponse(code ResponseCode, data interface{}, messageDetail string) (
 		message = "[UDB_Update_Failed] Could not complete request due to error while saving user data to udb"
 	case 24:
 		message = "[Leaderboard_Not_Found] Requested leaderboard not found"
+	case 25:
+		message = "[Target_Resource_Node_Unavailable] The specified resource node is not available at the current location"
+	case 26:
+		message = "[No_Such_Locale] The specified locale is not recognized"
+	case 27:
+		message = "[Insufficient_Resources] Could not complete requested action due to insufficient resources"
+	case 28:
+		message = "[Insufficient_Capacity] Could not complete requested action due to insufficient capacity"
+	case 29:
+		message = "[No_Merchant_Present] You must have a merchant present at the specified locale to view or trade at its market"
+	case 30:
+		message = "[No_Such_Market] There is no market at the specified locale"
+	case 31:
+		message = "[Resource_Not_Traded] The specified resource is not traded at this market"
+	case 32:
+		message = "[Not_Enough_Coins] Could not complete requested action due to insufficient coins"
+	case 33:
+		message = "[Golem_Wrong_Archetype] The specified golem's archetype cannot perform this action"
+	case 34:
+		message = "[No_Such_Recipe] The specified recipe is not recognized"
+	case 35:
+		message = "[Requirements_Not_Met] The requirements for this action have not been met"
+	case 36:
+		message = "[No_Such_Blueprint] The specified blueprint is not recognized"
+	case 37:
+		message = "[No_Path_Found] No chain of routes connects the specified locales"
+	case 38:
+		message = "[No_Such_Region] The specified region is not recognized"
+	case 39:
+		message = "[Golem_Exhausted] The golem does not have enough energy to start this task"
+	case 40:
+		message = "[Not_Enough_Invokers] Too few invoking invokers are at the locale to perform this ritual"
+	case 41:
+		message = "[No_Such_Research] The specified research is not recognized"
+	case 42:
+		message = "[No_Such_Mana_Upgrade] The specified mana upgrade is not recognized"
+	case 43:
+		message = "[Golem_Broken] The golem has no health left and must be repaired before working"
 	default:
 		message = "[Unexpected_Error] ResponseCode not in valid enum range! Contact developer"
 	}
@@ -0,0 +1,160 @@
+// Package scheduler runs registered jobs periodically in the background, for world state that changes without any user calling in
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/brct-james/guild-golems/log"
+)
+
+// A job run every Interval until the scheduler is stopped
+type Job struct {
+	Name string
+	Interval time.Duration
+	Run func() error
+}
+
+// Report of a job's most recent run, for the status endpoint
+type JobStatus struct {
+	Name string `json:"name" binding:"required"`
+	IntervalSeconds float64 `json:"interval-seconds" binding:"required"`
+	Runs int `json:"runs" binding:"required"`
+	Failures int `json:"failures" binding:"required"`
+	LastRun int64 `json:"last-run" binding:"required"`
+	LastDurationMs int64 `json:"last-duration-ms" binding:"required"`
+	LastError string `json:"last-error" binding:"required"`
+}
+
+// Returned when registering after Start or with an invalid job
+var ErrCannotRegister = errors.New("cannot register job")
+
+// Recorded as the run's error when a job panics
+var ErrJobPanicked = errors.New("job panicked")
+
+// Runs registered jobs on their own tickers, safe to query Status from other goroutines
+type Scheduler struct {
+	mutex sync.RWMutex
+	jobs []Job
+	statuses map[string]*JobStatus
+	started bool
+	stop chan struct{}
+	wg sync.WaitGroup
+}
+
+// Create a scheduler with no jobs
+func New() (*Scheduler) {
+	return &Scheduler{
+		jobs: make([]Job, 0),
+		statuses: make(map[string]*JobStatus),
+		stop: make(chan struct{}),
+	}
+}
+
+// Add job to the scheduler, must be called before Start
+func (s *Scheduler) Register(job Job) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.started {
+		return fmt.Errorf("%w: scheduler already started, cannot add %s", ErrCannotRegister, job.Name)
+	}
+	if job.Interval <= 0 || job.Run == nil {
+		return fmt.Errorf("%w: %s needs a positive interval and a run func", ErrCannotRegister, job.Name)
+	}
+	if _, ok := s.statuses[job.Name]; ok {
+		return fmt.Errorf("%w: a job named %s is already registered", ErrCannotRegister, job.Name)
+	}
+	s.jobs = append(s.jobs, job)
+	s.statuses[job.Name] = &JobStatus{Name: job.Name, IntervalSeconds: job.Interval.Seconds()}
+	return nil
+}
+
+// Start running every registered job in its own goroutine, jobs first run one interval after Start
+func (s *Scheduler) Start() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+	// A fresh channel each Start, as Stop closes the last one
+	s.stop = make(chan struct{})
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(job, s.stop)
+		log.Info.Printf("Scheduled job %s every %v", job.Name, job.Interval)
+	}
+}
+
+// Stop every job and wait for any in-progress runs to finish
+func (s *Scheduler) Stop() {
+	s.mutex.Lock()
+	if !s.started {
+		s.mutex.Unlock()
+		return
+	}
+	s.started = false
+	close(s.stop)
+	s.mutex.Unlock()
+	s.wg.Wait()
+	log.Info.Printf("Scheduler stopped")
+}
+
+// Get the status of every registered job, in registration order
+func (s *Scheduler) Status() ([]JobStatus) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		statuses = append(statuses, *s.statuses[job.Name])
+	}
+	return statuses
+}
+
+// Run job every interval until stop is closed
+func (s *Scheduler) loop(job Job, stop chan struct{}) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.runJob(job)
+		}
+	}
+}
+
+// Call job's Run, turning a panic into an error so one broken job cannot take down the server
+func callJob(job Job) (runErr error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			runErr = fmt.Errorf("%w: %v", ErrJobPanicked, recovered)
+		}
+	}()
+	return job.Run()
+}
+
+// Run job once and record how it went
+func (s *Scheduler) runJob(job Job) {
+	start := time.Now()
+	runErr := callJob(job)
+	duration := time.Since(start)
+	if runErr != nil {
+		log.Error.Printf("Scheduled job %s failed: %v", job.Name, runErr)
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	status := s.statuses[job.Name]
+	status.Runs++
+	status.LastRun = start.Unix()
+	status.LastDurationMs = duration.Milliseconds()
+	status.LastError = ""
+	if runErr != nil {
+		status.Failures++
+		status.LastError = runErr.Error()
+	}
+}
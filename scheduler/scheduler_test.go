@@ -0,0 +1,110 @@
+package scheduler
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Interval short enough to run jobs many times during a test
+var testInterval = 5 * time.Millisecond
+
+// Wait up to a second for check to pass
+func waitFor(t *testing.T, what string, check func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !check() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Get a job counting its runs in runs
+func newCountingJob(name string, runs *int32) (Job) {
+	return Job{Name: name, Interval: testInterval, Run: func() error {
+		atomic.AddInt32(runs, 1)
+		return nil
+	}}
+}
+
+func TestRegisterRejectsInvalidJobs(t *testing.T) {
+	s := New()
+	var runs int32
+	if err := s.Register(newCountingJob("tick", &runs)); err != nil {
+		t.Fatalf("expected first registration to succeed, got %v", err)
+	}
+	cases := map[string]Job{
+		"duplicate name": newCountingJob("tick", &runs),
+		"zero interval": {Name: "zero", Interval: 0, Run: func() error { return nil }},
+		"nil run": {Name: "nil", Interval: testInterval},
+	}
+	for name, job := range cases {
+		if err := s.Register(job); !errors.Is(err, ErrCannotRegister) {
+			t.Errorf("%s: expected ErrCannotRegister, got %v", name, err)
+		}
+	}
+	s.Start()
+	defer s.Stop()
+	if err := s.Register(newCountingJob("late", &runs)); !errors.Is(err, ErrCannotRegister) {
+		t.Errorf("registering after Start: expected ErrCannotRegister, got %v", err)
+	}
+}
+
+func TestStartRunsJobsAndStatusReportsThem(t *testing.T) {
+	s := New()
+	var runs int32
+	s.Register(newCountingJob("tick", &runs))
+	s.Register(Job{Name: "failing", Interval: testInterval, Run: func() error { return errors.New("no world db") }})
+	s.Start()
+	defer s.Stop()
+	waitFor(t, "both jobs to run twice", func() bool {
+		statuses := s.Status()
+		return statuses[0].Runs >= 2 && statuses[1].Runs >= 2
+	})
+	statuses := s.Status()
+	if statuses[0].Name != "tick" || statuses[1].Name != "failing" {
+		t.Fatalf("expected statuses in registration order, got %s then %s", statuses[0].Name, statuses[1].Name)
+	}
+	if statuses[0].LastError != "" || statuses[0].Failures != 0 || statuses[0].LastRun == 0 {
+		t.Errorf("expected a successful run of tick, got %+v", statuses[0])
+	}
+	if statuses[1].LastError != "no world db" || statuses[1].Failures != statuses[1].Runs {
+		t.Errorf("expected every run of failing to fail, got %+v", statuses[1])
+	}
+}
+
+func TestPanickingJobIsRecordedAndOthersKeepRunning(t *testing.T) {
+	s := New()
+	var runs int32
+	s.Register(Job{Name: "panicking", Interval: testInterval, Run: func() error { panic("nil market") }})
+	s.Register(newCountingJob("tick", &runs))
+	s.Start()
+	defer s.Stop()
+	waitFor(t, "the panicking job to run twice and tick to keep running", func() bool {
+		return s.Status()[0].Runs >= 2 && atomic.LoadInt32(&runs) >= 2
+	})
+	status := s.Status()[0]
+	if status.Failures != status.Runs || !strings.Contains(status.LastError, "nil market") {
+		t.Errorf("expected every panic recorded as a failure, got %+v", status)
+	}
+}
+
+func TestStopHaltsJobsAndStartResumesThem(t *testing.T) {
+	s := New()
+	var runs int32
+	s.Register(newCountingJob("tick", &runs))
+	s.Start()
+	waitFor(t, "tick to run", func() bool { return atomic.LoadInt32(&runs) >= 1 })
+	s.Stop()
+	stoppedAt := atomic.LoadInt32(&runs)
+	time.Sleep(5 * testInterval)
+	if after := atomic.LoadInt32(&runs); after != stoppedAt {
+		t.Fatalf("expected no runs after Stop, went from %d to %d", stoppedAt, after)
+	}
+	s.Start()
+	defer s.Stop()
+	waitFor(t, "tick to run again after restarting", func() bool { return atomic.LoadInt32(&runs) > stoppedAt })
+}
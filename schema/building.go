@@ -0,0 +1,155 @@
+// Package schema defines database and JSON schema as structs, as well as functions for creating and using these structs
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/brct-james/guild-golems/log"
+	"github.com/brct-james/guild-golems/rdb"
+)
+
+// Defines a structure engineers can construct at a locale
+// BuildWork is the number of seconds a single engineer needs to finish construction
+// StorageBonus raises the locale's storage capacity, UnlocksRecipeSymbols lists recipes that require this building
+type Blueprint struct {
+	Thing
+	Materials []RecipeComponent `json:"materials" binding:"required"`
+	BuildWork int `json:"build_work" binding:"required"`
+	StorageBonus float64 `json:"storage_bonus" binding:"required"`
+	UnlocksRecipeSymbols []string `json:"unlocks_recipe_symbols" binding:"required"`
+}
+
+// Defines a building owned by a user at a locale, used in udb, not json/wdb
+// Progress is measured in engineer-seconds of work out of the blueprint's BuildWork
+type Building struct {
+	BlueprintSymbol string `json:"blueprint-symbol" binding:"required"`
+	LocationSymbol string `json:"location-symbol" binding:"required"`
+	Progress float64 `json:"progress" binding:"required"`
+	BuildWork float64 `json:"build-work" binding:"required"`
+	MaterialsConsumed map[string]int `json:"materials-consumed" binding:"required"`
+	StorageBonus float64 `json:"storage-bonus" binding:"required"`
+	IsComplete bool `json:"is-complete" binding:"required"`
+	LastProgressTick int64 `json:"last-progress-tick" binding:"required"`
+}
+
+func NewBuilding(blueprint Blueprint, locationSymbol string, lastProgressTick int64) Building {
+	return Building{
+		BlueprintSymbol: blueprint.Symbol,
+		LocationSymbol: locationSymbol,
+		Progress: 0,
+		BuildWork: float64(blueprint.BuildWork),
+		MaterialsConsumed: make(map[string]int),
+		StorageBonus: blueprint.StorageBonus,
+		IsComplete: false,
+		LastProgressTick: lastProgressTick,
+	}
+}
+
+// Check whether the user has finished constructing blueprintSymbol at locationSymbol
+func HasCompletedBuilding(userData User, locationSymbol string, blueprintSymbol string) bool {
+	building, ok := userData.Buildings[locationSymbol][blueprintSymbol]
+	return ok && building.IsComplete
+}
+
+// Returned when a blueprint symbol is not recognized
+var ErrNoSuchBlueprint = errors.New("no such blueprint")
+
+// Unmarshals blueprint from json byte array
+func Blueprint_unmarshal_json(blueprint_json []byte) (Blueprint, error) {
+	log.Debug.Println("Unmarshalling blueprint.json")
+	var blueprint Blueprint
+	err := json.Unmarshal(blueprint_json, &blueprint)
+	if err != nil {
+		return Blueprint{}, err
+	}
+	return blueprint, nil
+}
+
+// Attempt to save blueprint, returns error or nil
+func Blueprint_save_to_db(wdb rdb.Database, blueprint Blueprint) (error) {
+	log.Debug.Printf("Saving blueprint to DB")
+	blueprintPath := fmt.Sprintf(".%s", blueprint.Symbol)
+	err := wdb.SetJsonData("blueprints", blueprintPath, blueprint)
+	return err
+}
+
+// Test: Get blueprint from db and compare with json
+func Test_blueprint_initialized(wdb rdb.Database, blueprint map[string]Blueprint) {
+	log.Debug.Printf("Comparing blueprint db to expected value")
+	blueprint_data, getErr := Blueprint_get_all_from_db(wdb)
+	if getErr != nil {
+		log.Error.Fatalf("Error encountered while testing blueprint during wdb initialization: %v", getErr)
+	}
+	success_str := fmt.Sprintf("%v", reflect.DeepEqual(blueprint_data, blueprint))
+	log.Test.Printf("%s DOES DB BLUEPRINT DEEPEQUAL JSON BLUEPRINT?", log.TestOutput(success_str, "true"))
+	if success_str != "true" {
+		log.Error.Fatalf("FAILED TEST WHILE INITIALIZING BLUEPRINT DB, LOADED JSON NOT MATCH DATABASE")
+	}
+}
+
+// Get json from db based on path
+func Blueprint_get_json_from_db(wdb rdb.Database, path string) ([]byte, error) {
+	log.Debug.Printf("Getting blueprint json from db")
+	bytes, err := wdb.GetJsonData("blueprints", path)
+	if err != nil {
+		return nil, err
+	}
+	return bytes, nil
+}
+
+// Get the blueprint specified by path from db
+func Blueprint_get_from_db(wdb rdb.Database, path string) (Blueprint, error) {
+	if strings.EqualFold(".", path) {
+		log.Error.Printf("Calling blueprint_get_from_db with . path, should use blueprint_get_all_from_db instead!")
+	}
+	log.Debug.Printf("Getting blueprint from db")
+	bytes, getErr := Blueprint_get_json_from_db(wdb, path)
+	if getErr != nil {
+		return Blueprint{}, getErr
+	}
+	blueprint, jsonErr := Blueprint_unmarshal_json(bytes)
+	if jsonErr != nil {
+		return Blueprint{}, jsonErr
+	}
+	return blueprint, nil
+}
+
+// Attempt to save all blueprints, returns error or nil
+func Blueprint_save_all_to_db(wdb rdb.Database, blueprints map[string]Blueprint) (error) {
+	log.Debug.Printf("Saving all blueprints to DB")
+	err := wdb.SetJsonData("blueprints", ".", blueprints)
+	return err
+}
+
+// Unmarshals all blueprints from json byte array
+func Blueprint_unmarshal_all_json(blueprint_json []byte) (map[string]Blueprint, error) {
+	log.Debug.Println("Unmarshalling blueprint.json")
+	nilRes := make(map[string]Blueprint)
+	var blueprints map[string]Blueprint
+	err := json.Unmarshal(blueprint_json, &blueprints)
+	if err != nil {
+		return nilRes, err
+	}
+	return blueprints, nil
+}
+
+// Gets all blueprints from DB
+func Blueprint_get_all_from_db(wdb rdb.Database) (map[string]Blueprint, error) {
+	log.Debug.Printf("Getting all blueprints from db")
+	nilRes := make(map[string]Blueprint)
+	bytes, getErr := Blueprint_get_json_from_db(wdb, ".")
+	if getErr != nil {
+		log.Debug.Printf("GetError %v", getErr)
+		return nilRes, getErr
+	}
+	blueprints, jsonErr := Blueprint_unmarshal_all_json(bytes)
+	if jsonErr != nil {
+		log.Debug.Printf("JsonError %v", jsonErr)
+		return nilRes, jsonErr
+	}
+	return blueprints, nil
+}
\ No newline at end of file
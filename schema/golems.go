@@ -15,14 +15,119 @@ type Golem struct {
 	Status string `json:"status" binding:"required"`
 	Capacity float64 `json:"capacity" binding:"required"`
 	TravelInfo GolemTravelInfo `json:"travel_info" binding:"required"`
+	HarvestInfo GolemHarvestInfo `json:"harvest_info" binding:"required"`
+	Cargo map[string]InventoryResource `json:"cargo" binding:"required"`
+	CraftInfo GolemCraftInfo `json:"craft_info" binding:"required"`
+	BuildInfo GolemBuildInfo `json:"build_info" binding:"required"`
+	Health int `json:"health" binding:"required"`
+	MaxHealth int `json:"max_health" binding:"required"`
+	EventLog []GolemEvent `json:"event_log" binding:"required"`
+	IdleSince int64 `json:"idle_since" binding:"required"`
+	Orders []GolemOrder `json:"orders" binding:"required"`
+	OrderIndex int `json:"order_index" binding:"required"`
+	RepeatOrders bool `json:"repeat_orders" binding:"required"`
+	Level int `json:"level" binding:"required"`
+	Experience int `json:"experience" binding:"required"`
+	// Mana paid for the ritual that summoned the golem, dismissal refunds part of it
+	SummonManaCost float64 `json:"summon_mana_cost"`
+	EnergyDetails
+}
+
+// Defines a queued order, Action is a status (e.g. traveling) or an instant action (load, unload)
+// Instructions take the same form as the instructions for the matching status update
+type GolemOrder struct {
+	Action string `json:"action" binding:"required"`
+	Instructions map[string]interface{} `json:"instructions" binding:"required"`
+}
+
+// Defines the structure for golem order queue requests
+type GolemOrdersBody struct {
+	Orders []GolemOrder `json:"orders" binding:"required"`
+	Repeat bool `json:"repeat" binding:"required"`
+}
+
+// Returned when an order or its instructions are malformed
+var ErrInvalidOrder = errors.New("invalid order")
+
+// Returned when an order's status is not allowed for the golem's archetype
+var ErrStatusNotAllowed = errors.New("status not allowed for archetype")
+
+// Returned when a golem has too little energy to start a task
+var ErrGolemExhausted = errors.New("golem exhausted")
+
+// Returned when a golem has no health left and must be repaired before working
+var ErrGolemBroken = errors.New("golem broken")
+
+// Returned when a golem's status, e.g. crafting, keeps it from being interrupted
+var ErrGolemBlocked = errors.New("golem in blocking status")
+
+// Returned when a golem is between locales and so cannot be e.g. dismissed
+var ErrGolemInTransit = errors.New("golem is in transit")
+
+// Defines the response for dismissing a golem
+// ManaRefundDue is the full refund, ManaRefunded the part that fit under the mana cap and ManaForfeited the rest
+type GolemDismissalResponse struct {
+	Golem Golem `json:"golem" binding:"required"`
+	ManaRefundDue float64 `json:"mana_refund_due" binding:"required"`
+	ManaRefunded float64 `json:"mana_refunded" binding:"required"`
+	ManaForfeited float64 `json:"mana_forfeited" binding:"required"`
+	Mana float64 `json:"mana" binding:"required"`
+}
+
+// Health golems are summoned with, travel damage can never take them below 0 and golems at 0 cannot work until repaired
+var Golem_Max_Health int = 100
+
+// Energy golems are summoned with and regenerate per second while idle
+var Golem_Energy_Cap float64 = 100
+var Golem_Energy_Regen float64 = 0.02
+
+// Number of events kept per golem, oldest are dropped first
+var Golem_Event_Log_Length int = 20
+
+// Defines an entry in a golem's event log, e.g. what happened on a trip
+type GolemEvent struct {
+	Timestamp int64 `json:"timestamp" binding:"required"`
+	Type string `json:"type" binding:"required"`
+	Description string `json:"description" binding:"required"`
 }
 
 // Defines relevant info for golems while traveling
+// Origin, destination and Progress (the fraction completed) describe the current hop, updated lazily by the gamelogic simulation
+// RemainingRoutes are the hops still to travel after the current one
 type GolemTravelInfo struct {
+	DepartureTime int64 `json:"departure_time" binding:"required"`
 	ArrivalTime int64 `json:"arrival_time" binding:"required"`
 	OriginSymbol string `json:"origin_symbol" binding:"required"`
 	DestinationSymbol string `json:"destination_symbol" binding:"required"`
 	RouteDanger int `json:"route_danger" binding:"required"`
+	Progress float64 `json:"progress" binding:"required"`
+	RemainingRoutes []string `json:"remaining_routes" binding:"required"`
+}
+
+// LocationSymbol of newly summoned golems unless a ritual says otherwise
+var Starting_Locale_Symbol string = "A-G"
+
+// LocationSymbol of golems between locales, they are not at any locale until they arrive
+var In_Transit_Location_Symbol string = "IN-TRANSIT"
+
+// Defines relevant info for golems while harvesting
+// LastHarvestTick is the timestamp from which the next harvest is counted
+// AutoDeposit unloads cargo into the locale inventory when full rather than stopping
+type GolemHarvestInfo struct {
+	NodeSymbol string `json:"node_symbol" binding:"required"`
+	LastHarvestTick int64 `json:"last_harvest_tick" binding:"required"`
+	AutoDeposit bool `json:"auto_deposit" binding:"required"`
+}
+
+// Defines relevant info for golems while crafting
+type GolemCraftInfo struct {
+	RecipeSymbol string `json:"recipe_symbol" binding:"required"`
+	CompletionTime int64 `json:"completion_time" binding:"required"`
+}
+
+// Defines relevant info for golems while building, progress is tracked on the building itself
+type GolemBuildInfo struct {
+	BlueprintSymbol string `json:"blueprint_symbol" binding:"required"`
 }
 
 // golem statuses map
@@ -34,7 +139,10 @@ var GolemStatuses = map[string]GolemStatus {
 	"idle": {Name:"Idle", IsBlocking: false},
 	"harvesting": {Name:"Harvesting", IsBlocking: false},
 	"traveling": {Name:"Traveling", IsBlocking: true},
+	"delivering": {Name:"Delivering", IsBlocking: true},
 	"invoking": {Name:"Invoking", IsBlocking: true},
+	"crafting": {Name:"Crafting", IsBlocking: true},
+	"building": {Name:"Building", IsBlocking: false},
 }
 
 // golem archetypes and abbreviations map
@@ -51,14 +159,22 @@ var GolemArchetypes = map[string]GolemArchetype {
 		AllowedStatuses: []string{"idle", "traveling", "harvesting"},
 	},
 	"courier": {Name:"Courier", Abbreviation:"COR",
-		AllowedStatuses: []string{"idle", "traveling"},
+		AllowedStatuses: []string{"idle", "traveling", "delivering"},
 	},
 	"artisan": {Name:"Artisan", Abbreviation:"ART",
-		AllowedStatuses: []string{"idle", "traveling"},
+		AllowedStatuses: []string{"idle", "traveling", "crafting"},
 	},
 	"merchant": {Name:"Merchant", Abbreviation:"MRC",
 		AllowedStatuses: []string{"idle", "traveling"},
 	},
+	"engineer": {Name:"Engineer", Abbreviation:"ENG",
+		AllowedStatuses: []string{"idle", "traveling", "building"},
+	},
+}
+
+// Defines the structure for golem load and unload requests, quantities keyed by resource symbol
+type GolemCargoTransferBody struct {
+	Resources map[string]int `json:"resources" binding:"required"`
 }
 
 // Defines the structure for golem status update requests
@@ -68,14 +184,14 @@ type GolemStatusUpdateBody struct {
 	Instructions interface{} `json:"instructions" binding:"required"`
 }
 
-
 // Defines the schema for EnergyDetails - a struct containing information on golem energy
-// type EnergyDetails struct {
-// 	Energy float64 `json:"energy" binding:"required"`
-// 	EnergyCap float64 `json:"energy-cap" binding:"required"`
-// 	EnergyRegen float64 `json:"energy-regen" binding:"required"`
-// 	LastEnergyTick int64 `json:"last-energy-tick" binding:"required"`
-// }
+// Energy drains while the golem works and regenerates at EnergyRegen per second while idle
+type EnergyDetails struct {
+	Energy float64 `json:"energy" binding:"required"`
+	EnergyCap float64 `json:"energy_cap" binding:"required"`
+	EnergyRegen float64 `json:"energy_regen" binding:"required"`
+	LastEnergyTick int64 `json:"last_energy_tick" binding:"required"`
+}
 
 func NewGolem(symbol string, archetype string, startingStatus string, capacity float64) Golem {
 	return Golem{
@@ -83,18 +199,62 @@ func NewGolem(symbol string, archetype string, startingStatus string, capacity f
 			Symbol: symbol,
 		},
 		Archetype: archetype,
-		LocationSymbol: "A-G",
+		LocationSymbol: Starting_Locale_Symbol,
 		Status: startingStatus,
 		Capacity: capacity,
 		TravelInfo: GolemTravelInfo{
+			DepartureTime: 0,
 			ArrivalTime: 0,
 			OriginSymbol: "",
 			DestinationSymbol: "",
 			RouteDanger: 0,
+			Progress: 0,
+			RemainingRoutes: make([]string, 0),
+		},
+		HarvestInfo: GolemHarvestInfo{
+			NodeSymbol: "",
+			LastHarvestTick: 0,
+			AutoDeposit: false,
+		},
+		Cargo: make(map[string]InventoryResource),
+		CraftInfo: GolemCraftInfo{
+			RecipeSymbol: "",
+			CompletionTime: 0,
+		},
+		BuildInfo: GolemBuildInfo{
+			BlueprintSymbol: "",
+		},
+		Health: Golem_Max_Health,
+		MaxHealth: Golem_Max_Health,
+		EventLog: make([]GolemEvent, 0),
+		IdleSince: 0,
+		Orders: make([]GolemOrder, 0),
+		OrderIndex: 0,
+		RepeatOrders: false,
+		Level: 1,
+		Experience: 0,
+		EnergyDetails: EnergyDetails{
+			Energy: Golem_Energy_Cap,
+			EnergyCap: Golem_Energy_Cap,
+			EnergyRegen: Golem_Energy_Regen,
+			// Set on the golem's first energy update
+			LastEnergyTick: 0,
 		},
 	}
 }
 
+// Append event to the golem's event log, dropping the oldest events past Golem_Event_Log_Length
+func AddGolemEvent(golem *Golem, timestamp int64, eventType string, description string) {
+	golem.EventLog = append(golem.EventLog, GolemEvent{
+		Timestamp: timestamp,
+		Type: eventType,
+		Description: description,
+	})
+	if len(golem.EventLog) > Golem_Event_Log_Length {
+		golem.EventLog = golem.EventLog[len(golem.EventLog)-Golem_Event_Log_Length:]
+	}
+}
+
 func IsStatusAllowedForArchetype(archetype string, newStatus string) (bool, error) {
 	archetypeInfo, ok := GolemArchetypes[archetype]
 	if !ok {
@@ -147,4 +307,8 @@ func FindIndexOfGolemWithSymbol(golems []Golem, symbol string) (bool, int) {
 		}
 	}
 	return false, -1
-}
\ No newline at end of file
+}
+// Get the capacity a golem has left after its current cargo
+func GetGolemFreeCapacity(golem Golem) float64 {
+	return golem.Capacity - CalculateUsedCapacity(golem.Cargo)
+}
@@ -0,0 +1,118 @@
+// Package schema defines database and JSON schema as structs, as well as functions for creating and using these structs
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Returned when removing more of a resource than an inventory holds
+var ErrInsufficientQuantity = errors.New("insufficient quantity in inventory")
+
+// Returned when resources would exceed the capacity of whatever holds them
+var ErrInsufficientCapacity = errors.New("insufficient capacity")
+
+// Returned when an action's locale, building or similar requirements are not met
+var ErrRequirementsNotMet = errors.New("requirements not met")
+
+// Defines the schema for LocationInventories - stacks of resources owned by the player at a certain location, keyed by resource symbol
+type LocationInventory struct {
+	LocationSymbol string `json:"location-symbol" binding:"required"`
+	Contents map[string]InventoryResource `json:"contents" binding:"required"`
+}
+
+func NewLocationInventory(locationSymbol string) LocationInventory {
+	return LocationInventory{
+		LocationSymbol: locationSymbol,
+		Contents: make(map[string]InventoryResource),
+	}
+}
+
+// Get the user's inventory at locationSymbol, creating it if it does not exist yet
+func GetLocationInventory(userData *User, locationSymbol string) LocationInventory {
+	if userData.Inventory == nil {
+		userData.Inventory = make(map[string]LocationInventory)
+	}
+	inventory, ok := userData.Inventory[locationSymbol]
+	if !ok || inventory.Contents == nil {
+		inventory = NewLocationInventory(locationSymbol)
+		userData.Inventory[locationSymbol] = inventory
+	}
+	return inventory
+}
+
+// Add quantity of resource to the stack in contents, creating the stack if needed
+func AddToInventory(contents map[string]InventoryResource, resource Resource, quantity int) error {
+	if quantity <= 0 {
+		return fmt.Errorf("cannot add non-positive quantity %d of %s", quantity, resource.Symbol)
+	}
+	stack, ok := contents[resource.Symbol]
+	if !ok {
+		stack = InventoryResource{Resource: resource, Quantity: 0}
+	}
+	stack.Quantity += quantity
+	contents[resource.Symbol] = stack
+	return nil
+}
+
+// Remove quantity of resourceSymbol from contents, deleting the stack when emptied
+// Returns the removed portion of the stack, or ErrInsufficientQuantity if contents holds too little
+func RemoveFromInventory(contents map[string]InventoryResource, resourceSymbol string, quantity int) (InventoryResource, error) {
+	if quantity <= 0 {
+		return InventoryResource{}, fmt.Errorf("cannot remove non-positive quantity %d of %s", quantity, resourceSymbol)
+	}
+	stack, ok := contents[resourceSymbol]
+	if !ok || stack.Quantity < quantity {
+		return InventoryResource{}, fmt.Errorf("%w: have %d %s but requires %d", ErrInsufficientQuantity, stack.Quantity, resourceSymbol, quantity)
+	}
+	stack.Quantity -= quantity
+	if stack.Quantity == 0 {
+		delete(contents, resourceSymbol)
+	} else {
+		contents[resourceSymbol] = stack
+	}
+	return InventoryResource{Resource: stack.Resource, Quantity: quantity}, nil
+}
+
+// Move quantity of resourceSymbol from one set of contents to another, leaving both untouched on error
+func TransferInventoryResource(from map[string]InventoryResource, to map[string]InventoryResource, resourceSymbol string, quantity int) error {
+	removed, removeErr := RemoveFromInventory(from, resourceSymbol, quantity)
+	if removeErr != nil {
+		return removeErr
+	}
+	return AddToInventory(to, removed.Resource, removed.Quantity)
+}
+
+// Add quantity of resource to the user's inventory at locationSymbol
+func AddToLocationInventory(userData *User, locationSymbol string, resource Resource, quantity int) error {
+	inventory := GetLocationInventory(userData, locationSymbol)
+	return AddToInventory(inventory.Contents, resource, quantity)
+}
+
+// Remove quantity of resourceSymbol from the user's inventory at locationSymbol
+func RemoveFromLocationInventory(userData *User, locationSymbol string, resourceSymbol string, quantity int) (InventoryResource, error) {
+	inventory := GetLocationInventory(userData, locationSymbol)
+	return RemoveFromInventory(inventory.Contents, resourceSymbol, quantity)
+}
+
+// Sum quantity * CapacityPerUnit across all stacks in contents
+func CalculateUsedCapacity(contents map[string]InventoryResource) float64 {
+	used := 0.0
+	for _, stack := range contents {
+		used += float64(stack.Quantity) * stack.CapacityPerUnit
+	}
+	return used
+}
+
+// Get how many units of resource fit into freeCapacity
+func UnitsThatFit(resource Resource, freeCapacity float64) int {
+	if freeCapacity <= 0 && resource.CapacityPerUnit > 0 {
+		return 0
+	}
+	if resource.CapacityPerUnit <= 0 {
+		return math.MaxInt32
+	}
+	// Small epsilon so fractional capacities like 0.25 do not lose a unit to float error
+	return int(math.Floor(freeCapacity/resource.CapacityPerUnit + 1e-9))
+}
@@ -1,6 +1,10 @@
 // Package schema defines database and JSON schema as structs, as well as functions for creating and using these structs
 package schema
 
+import (
+	"sync"
+)
+
 type Leaderboard struct {
 	Thing
 	Users []LeaderboardEntry `json:"users" binding:"required"`
@@ -25,6 +29,40 @@ var Leaderboards = map[string]Leaderboard {
 	"achievement-leaders": {Thing:Thing{HasSymbol:HasSymbol{Symbol:"achievement-leaders"}, Name:"Achievement Leaders", Description:"Top 10 Users by Achievements Completed"}, Users:make([]LeaderboardEntry, 0)},
 }
 
+// Guards Leaderboards, which are regenerated in the background while handlers read them
+var leaderboardsMutex sync.RWMutex
+
+// Get a copy of the leaderboard with symbol, bool is if found
+func GetLeaderboard(symbol string) (Leaderboard, bool) {
+	leaderboardsMutex.RLock()
+	defer leaderboardsMutex.RUnlock()
+	board, ok := Leaderboards[symbol]
+	return board, ok
+}
+
+// Get a copy of every leaderboard
+func GetAllLeaderboards() ([]Leaderboard) {
+	leaderboardsMutex.RLock()
+	defer leaderboardsMutex.RUnlock()
+	boards := make([]Leaderboard, 0, len(Leaderboards))
+	for _, board := range Leaderboards {
+		boards = append(boards, board)
+	}
+	return boards
+}
+
+// Replace the ranked users of the leaderboard with symbol, unknown symbols are ignored
+func SetLeaderboardUsers(symbol string, users []LeaderboardEntry) {
+	leaderboardsMutex.Lock()
+	defer leaderboardsMutex.Unlock()
+	board, ok := Leaderboards[symbol]
+	if !ok {
+		return
+	}
+	board.Users = users
+	Leaderboards[symbol] = board
+}
+
 type LeaderboardDescriptionResponse struct {
 	Symbol string `json:"symbol" binding:"required"`
 	Name string `json:"name" binding:"required"`
@@ -18,6 +18,15 @@ type Locale struct {
 	RouteSymbols []string `json:"route_symbols" binding:"required"`
 }
 
+// Defines the response for /api/v0/locales/{symbol}, the locale along with its region, routes and resource nodes
+type LocaleDetailResponse struct {
+	Locale Locale `json:"locale" binding:"required"`
+	RegionSymbol string `json:"region_symbol" binding:"required"`
+	Routes map[string]Route `json:"routes" binding:"required"`
+	ResourceNodes map[string]ResourceNode `json:"resource_nodes" binding:"required"`
+	ResourceNodeStates map[string]ResourceNodeState `json:"resource_node_states" binding:"required"`
+}
+
 // Unmarshals locale from json byte array
 func Locale_unmarshal_json(locale_json []byte) (Locale, error) {
 	log.Debug.Println("Unmarshalling locale.json")
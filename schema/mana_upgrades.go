@@ -0,0 +1,96 @@
+// Package schema defines database and JSON schema as structs, as well as functions for creating and using these structs
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/brct-james/guild-golems/log"
+)
+
+// Defines a buyable upgrade raising the user's Stat, mana_cap or mana_regen, by AmountPerLevel for each level bought
+// Costs are for the first level, each level after costs CostGrowth times the one before
+// ResourceCosts are taken from the inventory at the locale the upgrade is bought from
+type ManaUpgrade struct {
+	Thing
+	Stat string `json:"stat" binding:"required"`
+	AmountPerLevel float64 `json:"amount-per-level" binding:"required"`
+	MaxLevel int `json:"max-level" binding:"required"`
+	ManaCost float64 `json:"mana-cost" binding:"required"`
+	CoinCost uint64 `json:"coin-cost" binding:"required"`
+	ResourceCosts []RecipeComponent `json:"resource-costs" binding:"required"`
+	CostGrowth float64 `json:"cost-growth" binding:"required"`
+}
+
+// Defines the cost of one level of a mana upgrade
+type ManaUpgradeCost struct {
+	ManaCost float64 `json:"mana-cost" binding:"required"`
+	CoinCost uint64 `json:"coin-cost" binding:"required"`
+	ResourceCosts []RecipeComponent `json:"resource-costs" binding:"required"`
+}
+
+// Defines the user's level of a mana upgrade for the /my/mana-upgrades endpoint, NextCost is nil at MaxLevel
+type ManaUpgradeStatus struct {
+	ManaUpgrade
+	Level int `json:"level" binding:"required"`
+	NextCost *ManaUpgradeCost `json:"next-cost" binding:"required"`
+}
+
+// Defines how the user's mana cap and regen are made up, for the /my/account endpoint
+// InvokerRegen is keyed by locale, it fills the ritual circle there before any flows into the user's mana
+type ManaBreakdown struct {
+	BaseCap float64 `json:"base-cap" binding:"required"`
+	UpgradeCap float64 `json:"upgrade-cap" binding:"required"`
+	ManaCap float64 `json:"mana-cap" binding:"required"`
+	BaseRegen float64 `json:"base-regen" binding:"required"`
+	UpgradeRegen float64 `json:"upgrade-regen" binding:"required"`
+	BuffRegen float64 `json:"buff-regen" binding:"required"`
+	InvokerRegen map[string]float64 `json:"invoker-regen" binding:"required"`
+	TotalRegen float64 `json:"total-regen" binding:"required"`
+}
+
+// Stats a mana upgrade can raise
+var UpgradeableManaStats = map[string]bool{"mana_cap": true, "mana_regen": true}
+
+// Returned when a mana upgrade symbol is not recognized
+var ErrNoSuchManaUpgrade = errors.New("no such mana upgrade")
+
+// mana upgrade map, loaded from json at startup
+var ManaUpgrades = map[string]ManaUpgrade {}
+
+// Unmarshals all mana upgrades from json byte array
+func ManaUpgrade_unmarshal_all_json(upgrade_json []byte) (map[string]ManaUpgrade, error) {
+	log.Debug.Println("Unmarshalling mana_upgrades.json")
+	nilRes := make(map[string]ManaUpgrade)
+	var upgrades map[string]ManaUpgrade
+	err := json.Unmarshal(upgrade_json, &upgrades)
+	if err != nil {
+		return nilRes, err
+	}
+	return upgrades, nil
+}
+
+// Check every mana upgrade raises a known stat and its costs never shrink
+func ValidateManaUpgrades(upgrades map[string]ManaUpgrade) error {
+	for symbol, upgrade := range upgrades {
+		if upgrade.Symbol != symbol {
+			return fmt.Errorf("mana upgrade %s has mismatched symbol %s", symbol, upgrade.Symbol)
+		}
+		if !UpgradeableManaStats[upgrade.Stat] {
+			return fmt.Errorf("mana upgrade %s raises unknown stat %s", symbol, upgrade.Stat)
+		}
+		if upgrade.AmountPerLevel <= 0 || upgrade.MaxLevel <= 0 {
+			return fmt.Errorf("mana upgrade %s needs a positive amount per level and max level", symbol)
+		}
+		if upgrade.ManaCost < 0 || upgrade.CostGrowth < 1 {
+			return fmt.Errorf("mana upgrade %s has negative mana cost or cost growth below 1", symbol)
+		}
+		for _, cost := range upgrade.ResourceCosts {
+			if cost.Quantity <= 0 {
+				return fmt.Errorf("mana upgrade %s has non-positive quantity of %s", symbol, cost.ResourceSymbol)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+// Package schema defines database and JSON schema as structs, as well as functions for creating and using these structs
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/brct-james/guild-golems/log"
+	"github.com/brct-james/guild-golems/rdb"
+)
+
+// Defines the NPC market at a locale, Symbol matches the locale symbol
+// LastDriftTick is the timestamp stock was last drifted toward BaseStock
+type Market struct {
+	Thing
+	LastDriftTick int64 `json:"last_drift_tick" binding:"required"`
+	Listings map[string]MarketListing `json:"listings" binding:"required"`
+}
+
+// Defines how a market prices a resource
+// Price at a stock level is BasePrice * (BaseStock / Stock) ^ Elasticity, so buying drains stock and raises prices while selling does the opposite
+// Stock drifts back toward BaseStock, halving the difference every DriftHalfLife seconds (0 disables drift)
+// BuyPrice is what players currently pay per unit, SellPrice is what players currently receive per unit, both are derived from the above
+type MarketListing struct {
+	ResourceSymbol string `json:"resource_symbol" binding:"required"`
+	BasePrice float64 `json:"base_price" binding:"required"`
+	BaseStock float64 `json:"base_stock" binding:"required"`
+	Stock float64 `json:"stock" binding:"required"`
+	Elasticity float64 `json:"elasticity" binding:"required"`
+	Spread float64 `json:"spread" binding:"required"`
+	DriftHalfLife float64 `json:"drift_half_life" binding:"required"`
+	BuyPrice uint64 `json:"buy_price" binding:"required"`
+	SellPrice uint64 `json:"sell_price" binding:"required"`
+}
+
+// Defines a completed trade between a merchant and a market, used in responses
+type MarketTransaction struct {
+	MerchantSymbol string `json:"merchant_symbol" binding:"required"`
+	LocaleSymbol string `json:"locale_symbol" binding:"required"`
+	ResourceSymbol string `json:"resource_symbol" binding:"required"`
+	Action string `json:"action" binding:"required"`
+	Quantity int `json:"quantity" binding:"required"`
+	UnitPrice uint64 `json:"unit_price" binding:"required"`
+	TotalPrice uint64 `json:"total_price" binding:"required"`
+	Coins uint64 `json:"coins" binding:"required"`
+}
+
+// Defines the structure for merchant buy and sell requests
+type MarketOrderBody struct {
+	ResourceSymbol string `json:"resource_symbol" binding:"required"`
+	Quantity int `json:"quantity" binding:"required"`
+}
+
+// Returned when a user cannot afford a purchase
+var ErrInsufficientCoins = errors.New("insufficient coins")
+
+// Returned when a market has no listing for a resource
+var ErrResourceNotTraded = errors.New("resource not traded at this market")
+
+// Unmarshals market from json byte array
+func Market_unmarshal_json(market_json []byte) (Market, error) {
+	log.Debug.Println("Unmarshalling market.json")
+	var market Market
+	err := json.Unmarshal(market_json, &market)
+	if err != nil {
+		return Market{}, err
+	}
+	return market, nil
+}
+
+// Attempt to save market, returns error or nil
+func Market_save_to_db(wdb rdb.Database, market Market) (error) {
+	log.Debug.Printf("Saving market to DB")
+	marketPath := fmt.Sprintf(".%s", market.Symbol)
+	err := wdb.SetJsonData("markets", marketPath, market)
+	return err
+}
+
+// Atomically update the market at path, retrying if it is changed concurrently e.g. by a trade
+func Market_update_in_db(wdb rdb.Database, path string, update func(Market) (Market, error)) (error) {
+	log.Debug.Printf("Updating market in db")
+	return wdb.UpdateJsonData("markets", path, func(current []byte) (interface{}, error) {
+		market, jsonErr := Market_unmarshal_json(current)
+		if jsonErr != nil {
+			return nil, jsonErr
+		}
+		return update(market)
+	})
+}
+
+// Test: Get market from db and compare with json
+func Test_market_initialized(wdb rdb.Database, market map[string]Market) {
+	log.Debug.Printf("Comparing market db to expected value")
+	market_data, getErr := Market_get_all_from_db(wdb)
+	if getErr != nil {
+		log.Error.Fatalf("Error encountered while testing market during wdb initialization: %v", getErr)
+	}
+	success_str := fmt.Sprintf("%v", reflect.DeepEqual(market_data, market))
+	log.Test.Printf("%s DOES DB MARKET DEEPEQUAL JSON MARKET?", log.TestOutput(success_str, "true"))
+	if success_str != "true" {
+		log.Error.Fatalf("FAILED TEST WHILE INITIALIZING MARKET DB, LOADED JSON NOT MATCH DATABASE")
+	}
+}
+
+// Get json from db based on path
+func Market_get_json_from_db(wdb rdb.Database, path string) ([]byte, error) {
+	log.Debug.Printf("Getting market json from db")
+	bytes, err := wdb.GetJsonData("markets", path)
+	if err != nil {
+		return nil, err
+	}
+	return bytes, nil
+}
+
+// Get the market specified by path from db
+func Market_get_from_db(wdb rdb.Database, path string) (Market, error) {
+	if strings.EqualFold(".", path) {
+		log.Error.Printf("Calling market_get_from_db with . path, should use market_get_all_from_db instead!")
+	}
+	log.Debug.Printf("Getting market from db")
+	bytes, getErr := Market_get_json_from_db(wdb, path)
+	if getErr != nil {
+		return Market{}, getErr
+	}
+	market, jsonErr := Market_unmarshal_json(bytes)
+	if jsonErr != nil {
+		return Market{}, jsonErr
+	}
+	return market, nil
+}
+
+// Attempt to save all markets, returns error or nil
+func Market_save_all_to_db(wdb rdb.Database, markets map[string]Market) (error) {
+	log.Debug.Printf("Saving all markets to DB")
+	err := wdb.SetJsonData("markets", ".", markets)
+	return err
+}
+
+// Unmarshals all markets from json byte array
+func Market_unmarshal_all_json(market_json []byte) (map[string]Market, error) {
+	log.Debug.Println("Unmarshalling market.json")
+	nilRes := make(map[string]Market)
+	var markets map[string]Market
+	err := json.Unmarshal(market_json, &markets)
+	if err != nil {
+		return nilRes, err
+	}
+	return markets, nil
+}
+
+// Gets all markets from DB
+func Market_get_all_from_db(wdb rdb.Database) (map[string]Market, error) {
+	log.Debug.Printf("Getting all markets from db")
+	nilRes := make(map[string]Market)
+	bytes, getErr := Market_get_json_from_db(wdb, ".")
+	if getErr != nil {
+		log.Debug.Printf("GetError %v", getErr)
+		return nilRes, getErr
+	}
+	markets, jsonErr := Market_unmarshal_all_json(bytes)
+	if jsonErr != nil {
+		log.Debug.Printf("JsonError %v", jsonErr)
+		return nilRes, jsonErr
+	}
+	return markets, nil
+}
\ No newline at end of file
@@ -0,0 +1,129 @@
+// Package schema defines database and JSON schema as structs, as well as functions for creating and using these structs
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/brct-james/guild-golems/log"
+	"github.com/brct-james/guild-golems/rdb"
+)
+
+// Defines a recipe artisans use to convert input resources into output resources
+// CraftTime in seconds, RequiredLocaleSymbol and RequiredBuildingSymbol are optional (empty for none)
+type Recipe struct {
+	Thing
+	Inputs []RecipeComponent `json:"inputs" binding:"required"`
+	Outputs []RecipeComponent `json:"outputs" binding:"required"`
+	CraftTime int `json:"craft_time" binding:"required"`
+	RequiredLocaleSymbol string `json:"required_locale_symbol" binding:"required"`
+	RequiredBuildingSymbol string `json:"required_building_symbol" binding:"required"`
+}
+
+// Defines a quantity of resource consumed or produced by a recipe
+type RecipeComponent struct {
+	ResourceSymbol string `json:"resource_symbol" binding:"required"`
+	Quantity int `json:"quantity" binding:"required"`
+}
+
+// Returned when a recipe symbol is not recognized
+var ErrNoSuchRecipe = errors.New("no such recipe")
+
+// Unmarshals recipe from json byte array
+func Recipe_unmarshal_json(recipe_json []byte) (Recipe, error) {
+	log.Debug.Println("Unmarshalling recipe.json")
+	var recipe Recipe
+	err := json.Unmarshal(recipe_json, &recipe)
+	if err != nil {
+		return Recipe{}, err
+	}
+	return recipe, nil
+}
+
+// Attempt to save recipe, returns error or nil
+func Recipe_save_to_db(wdb rdb.Database, recipe Recipe) (error) {
+	log.Debug.Printf("Saving recipe to DB")
+	recipePath := fmt.Sprintf(".%s", recipe.Symbol)
+	err := wdb.SetJsonData("recipes", recipePath, recipe)
+	return err
+}
+
+// Test: Get recipe from db and compare with json
+func Test_recipe_initialized(wdb rdb.Database, recipe map[string]Recipe) {
+	log.Debug.Printf("Comparing recipe db to expected value")
+	recipe_data, getErr := Recipe_get_all_from_db(wdb)
+	if getErr != nil {
+		log.Error.Fatalf("Error encountered while testing recipe during wdb initialization: %v", getErr)
+	}
+	success_str := fmt.Sprintf("%v", reflect.DeepEqual(recipe_data, recipe))
+	log.Test.Printf("%s DOES DB RECIPE DEEPEQUAL JSON RECIPE?", log.TestOutput(success_str, "true"))
+	if success_str != "true" {
+		log.Error.Fatalf("FAILED TEST WHILE INITIALIZING RECIPE DB, LOADED JSON NOT MATCH DATABASE")
+	}
+}
+
+// Get json from db based on path
+func Recipe_get_json_from_db(wdb rdb.Database, path string) ([]byte, error) {
+	log.Debug.Printf("Getting recipe json from db")
+	bytes, err := wdb.GetJsonData("recipes", path)
+	if err != nil {
+		return nil, err
+	}
+	return bytes, nil
+}
+
+// Get the recipe specified by path from db
+func Recipe_get_from_db(wdb rdb.Database, path string) (Recipe, error) {
+	if strings.EqualFold(".", path) {
+		log.Error.Printf("Calling recipe_get_from_db with . path, should use recipe_get_all_from_db instead!")
+	}
+	log.Debug.Printf("Getting recipe from db")
+	bytes, getErr := Recipe_get_json_from_db(wdb, path)
+	if getErr != nil {
+		return Recipe{}, getErr
+	}
+	recipe, jsonErr := Recipe_unmarshal_json(bytes)
+	if jsonErr != nil {
+		return Recipe{}, jsonErr
+	}
+	return recipe, nil
+}
+
+// Attempt to save all recipes, returns error or nil
+func Recipe_save_all_to_db(wdb rdb.Database, recipes map[string]Recipe) (error) {
+	log.Debug.Printf("Saving all recipes to DB")
+	err := wdb.SetJsonData("recipes", ".", recipes)
+	return err
+}
+
+// Unmarshals all recipes from json byte array
+func Recipe_unmarshal_all_json(recipe_json []byte) (map[string]Recipe, error) {
+	log.Debug.Println("Unmarshalling recipe.json")
+	nilRes := make(map[string]Recipe)
+	var recipes map[string]Recipe
+	err := json.Unmarshal(recipe_json, &recipes)
+	if err != nil {
+		return nilRes, err
+	}
+	return recipes, nil
+}
+
+// Gets all recipes from DB
+func Recipe_get_all_from_db(wdb rdb.Database) (map[string]Recipe, error) {
+	log.Debug.Printf("Getting all recipes from db")
+	nilRes := make(map[string]Recipe)
+	bytes, getErr := Recipe_get_json_from_db(wdb, ".")
+	if getErr != nil {
+		log.Debug.Printf("GetError %v", getErr)
+		return nilRes, getErr
+	}
+	recipes, jsonErr := Recipe_unmarshal_all_json(bytes)
+	if jsonErr != nil {
+		log.Debug.Printf("JsonError %v", jsonErr)
+		return nilRes, jsonErr
+	}
+	return recipes, nil
+}
\ No newline at end of file
@@ -11,10 +11,85 @@ import (
 	"github.com/brct-james/guild-golems/rdb"
 )
 
+// Defines the characteristics of Regions (e.g. nations) which contain locales
+// Routes may only cross into regions listed in BorderRegionSymbols
 type Region struct {
 	Thing
 	BorderRegionSymbols []string `json:"border_region_symbols" binding:"required"`
 	LocaleSymbols []string `json:"locale_symbols" binding:"required"`
+	EntryRequirements RegionEntryRequirements `json:"entry_requirements"`
+}
+
+// Defines what a user needs before their golems may enter a region from another, empty fields are not required
+type RegionEntryRequirements struct {
+	MinTitle string `json:"min_title"`
+	RequiredRitualSymbol string `json:"required_ritual"`
+}
+
+// Defines the response for /api/v0/regions/{symbol}, the region along with its locales
+type RegionDetailResponse struct {
+	Region Region `json:"region" binding:"required"`
+	Locales map[string]Locale `json:"locales" binding:"required"`
+}
+
+// Get the symbol of the region containing localeSymbol
+func GetRegionSymbolForLocale(regions map[string]Region, localeSymbol string) (string, bool) {
+	for regionSymbol, region := range regions {
+		for _, symbol := range region.LocaleSymbols {
+			if strings.EqualFold(symbol, localeSymbol) {
+				return regionSymbol, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Check whether borderSymbol is listed as a border of region
+func DoesRegionBorder(region Region, borderSymbol string) bool {
+	for _, symbol := range region.BorderRegionSymbols {
+		if strings.EqualFold(symbol, borderSymbol) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check every region's entry requirements name a known title and ritual, an unknown one could never be met
+func ValidateRegionEntryRequirements(regions map[string]Region, rituals map[string]Ritual) error {
+	for symbol, region := range regions {
+		requirements := region.EntryRequirements
+		if GetTitleRank(requirements.MinTitle) < 0 {
+			return fmt.Errorf("region %s requires unknown title %s", symbol, requirements.MinTitle)
+		}
+		if _, ok := rituals[requirements.RequiredRitualSymbol]; requirements.RequiredRitualSymbol != "" && !ok {
+			return fmt.Errorf("region %s requires unknown ritual %s", symbol, requirements.RequiredRitualSymbol)
+		}
+	}
+	return nil
+}
+
+// Check every route connects known locales, and that routes between regions only cross defined borders
+func ValidateRouteBorders(routes map[string]Route, locales map[string]Locale, regions map[string]Region) error {
+	for routeSymbol := range routes {
+		originSymbol, destinationSymbol, parseErr := ParseRouteSymbol(routeSymbol)
+		if parseErr != nil {
+			return parseErr
+		}
+		for _, localeSymbol := range []string{originSymbol, destinationSymbol} {
+			if _, ok := locales[localeSymbol]; !ok {
+				return fmt.Errorf("route %s references unknown locale %s", routeSymbol, localeSymbol)
+			}
+		}
+		originRegion, originFound := GetRegionSymbolForLocale(regions, originSymbol)
+		destinationRegion, destinationFound := GetRegionSymbolForLocale(regions, destinationSymbol)
+		if !originFound || !destinationFound {
+			return fmt.Errorf("route %s connects a locale which is not in any region", routeSymbol)
+		}
+		if originRegion != destinationRegion && !DoesRegionBorder(regions[originRegion], destinationRegion) {
+			return fmt.Errorf("route %s crosses from %s into %s but they do not share a border", routeSymbol, originRegion, destinationRegion)
+		}
+	}
+	return nil
 }
 
 // Unmarshals region from json byte array
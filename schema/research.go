@@ -0,0 +1,94 @@
+// Package schema defines database and JSON schema as structs, as well as functions for creating and using these structs
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/brct-james/guild-golems/log"
+)
+
+// Defines an item in the research tree, finishing it teaches the user RitualSymbol
+// PrerequisiteRituals must all be known to start it, ResourceCosts are taken from the inventory at the locale research starts from
+// ResearchTime in seconds
+type Research struct {
+	Thing
+	RitualSymbol string `json:"ritual-symbol" binding:"required"`
+	PrerequisiteRituals []string `json:"prerequisite-rituals" binding:"required"`
+	ManaCost float64 `json:"mana-cost" binding:"required"`
+	CoinCost uint64 `json:"coin-cost" binding:"required"`
+	ResourceCosts []RecipeComponent `json:"resource-costs" binding:"required"`
+	ResearchTime int `json:"research-time" binding:"required"`
+}
+
+// Defines the research the user is working on, ResearchSymbol is empty when there is none
+type ResearchProgress struct {
+	ResearchSymbol string `json:"research-symbol" binding:"required"`
+	StartTime int64 `json:"start-time" binding:"required"`
+	CompletionTime int64 `json:"completion-time" binding:"required"`
+}
+
+// Defines the body of requests that may take resource costs from an inventory, e.g. research and mana upgrades
+// LocaleSymbol defaults to Starting_Locale_Symbol
+type PurchaseBody struct {
+	LocaleSymbol string `json:"locale_symbol"`
+}
+
+// Defines how far the user is with a research item for the /my/research endpoint
+// Status is one of learned, in-progress, available or locked, Progress runs from 0 to 1
+type ResearchStatus struct {
+	Research
+	Status string `json:"status" binding:"required"`
+	Progress float64 `json:"progress" binding:"required"`
+	CompletionTime int64 `json:"completion-time" binding:"required"`
+}
+
+// Returned when a research symbol is not recognized
+var ErrNoSuchResearch = errors.New("no such research")
+
+// research tree map, loaded from json at startup
+var ResearchTree = map[string]Research {}
+
+// Unmarshals all research from json byte array
+func Research_unmarshal_all_json(research_json []byte) (map[string]Research, error) {
+	log.Debug.Println("Unmarshalling research.json")
+	nilRes := make(map[string]Research)
+	var research map[string]Research
+	err := json.Unmarshal(research_json, &research)
+	if err != nil {
+		return nilRes, err
+	}
+	return research, nil
+}
+
+// Check every research item teaches and requires rituals that exist, and that no two teach the same ritual
+func ValidateResearch(research map[string]Research, rituals map[string]Ritual) error {
+	taughtBy := make(map[string]string)
+	for symbol, item := range research {
+		if item.Symbol != symbol {
+			return fmt.Errorf("research %s has mismatched symbol %s", symbol, item.Symbol)
+		}
+		if _, ok := rituals[item.RitualSymbol]; !ok {
+			return fmt.Errorf("research %s teaches unknown ritual %s", symbol, item.RitualSymbol)
+		}
+		if other, ok := taughtBy[item.RitualSymbol]; ok {
+			return fmt.Errorf("research %s and %s both teach %s", symbol, other, item.RitualSymbol)
+		}
+		taughtBy[item.RitualSymbol] = symbol
+		for _, prerequisite := range item.PrerequisiteRituals {
+			if _, ok := rituals[prerequisite]; !ok {
+				return fmt.Errorf("research %s requires unknown ritual %s", symbol, prerequisite)
+			}
+		}
+		if item.ManaCost < 0 || item.ResearchTime < 0 {
+			return fmt.Errorf("research %s has negative mana cost or research time", symbol)
+		}
+		for _, cost := range item.ResourceCosts {
+			if cost.Quantity <= 0 {
+				return fmt.Errorf("research %s has non-positive quantity of %s", symbol, cost.ResourceSymbol)
+			}
+		}
+	}
+	return nil
+}
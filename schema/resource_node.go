@@ -3,6 +3,7 @@ package schema
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -12,10 +13,21 @@ import (
 )
 
 // Defines harvestable resource node
+// MaxQuantity is the number of harvests the node holds when full, 0 for infinite
+// ReplenishRate is the number of harvests restored per second
 type ResourceNode struct {
 	Thing
 	HarvestTime int `json:"harvest_time" binding:"required"`
 	DropTables []DropTable `json:"drop_tables" binding:"required"`
+	MaxQuantity int `json:"max_quantity"`
+	ReplenishRate float64 `json:"replenish_rate"`
+}
+
+// Defines the shared, mutable state of a finite resource node, stored in wdb under resourcenodestates
+// Quantity is the number of harvests left as of LastReplenishTick
+type ResourceNodeState struct {
+	Quantity float64 `json:"quantity" binding:"required"`
+	LastReplenishTick int64 `json:"last_replenish_tick" binding:"required"`
 }
 
 // Defines droptables
@@ -25,6 +37,9 @@ type DropTable struct {
 	HarvestAmount int `json:"harvest_amount" binding:"required"`
 }
 
+// Returned when a resource node does not exist or is not at the golem's locale
+var ErrResourceNodeUnavailable = errors.New("resource node unavailable")
+
 // Unmarshals resourcenode from json byte array
 func ResourceNode_unmarshal_json(resourcenode_json []byte) (ResourceNode, error) {
 	log.Debug.Println("Unmarshalling resourcenode.json")
@@ -119,4 +134,75 @@ func ResourceNode_get_all_from_db(wdb rdb.Database) (map[string]ResourceNode, er
 		return nilRes, jsonErr
 	}
 	return resourcenodes, nil
-}
\ No newline at end of file
+}
+
+// Check whether the node has a limited quantity
+func IsResourceNodeFinite(node ResourceNode) bool {
+	return node.MaxQuantity > 0
+}
+
+// Create full states for every finite resource node, keyed by node symbol
+func NewResourceNodeStates(resourcenodes map[string]ResourceNode, lastReplenishTick int64) (map[string]ResourceNodeState) {
+	states := make(map[string]ResourceNodeState)
+	for symbol, node := range resourcenodes {
+		if IsResourceNodeFinite(node) {
+			states[symbol] = ResourceNodeState{
+				Quantity: float64(node.MaxQuantity),
+				LastReplenishTick: lastReplenishTick,
+			}
+		}
+	}
+	return states
+}
+
+// Attempt to save all resourcenode states, returns error or nil
+func ResourceNodeState_save_all_to_db(wdb rdb.Database, states map[string]ResourceNodeState) (error) {
+	log.Debug.Printf("Saving all resourcenode states to DB")
+	err := wdb.SetJsonData("resourcenodestates", ".", states)
+	return err
+}
+
+// Gets all resourcenode states from DB
+func ResourceNodeState_get_all_from_db(wdb rdb.Database) (map[string]ResourceNodeState, error) {
+	log.Debug.Printf("Getting all resourcenode states from db")
+	nilRes := make(map[string]ResourceNodeState)
+	bytes, getErr := wdb.GetJsonData("resourcenodestates", ".")
+	if getErr != nil {
+		log.Debug.Printf("GetError %v", getErr)
+		return nilRes, getErr
+	}
+	var states map[string]ResourceNodeState
+	jsonErr := json.Unmarshal(bytes, &states)
+	if jsonErr != nil {
+		log.Debug.Printf("JsonError %v", jsonErr)
+		return nilRes, jsonErr
+	}
+	return states, nil
+}
+
+// Atomically update the state of the resourcenode at path, update receives the stored state and returns the state to save
+func ResourceNodeState_update_in_db(wdb rdb.Database, path string, update func(ResourceNodeState) (ResourceNodeState, error)) (error) {
+	log.Debug.Printf("Updating resourcenode state in db")
+	return wdb.UpdateJsonData("resourcenodestates", path, func(current []byte) (interface{}, error) {
+		var state ResourceNodeState
+		jsonErr := json.Unmarshal(current, &state)
+		if jsonErr != nil {
+			return nil, jsonErr
+		}
+		return update(state)
+	})
+}
+
+// Test: Get resourcenode states from db and compare with expected
+func Test_resourcenodestate_initialized(wdb rdb.Database, states map[string]ResourceNodeState) {
+	log.Debug.Printf("Comparing resourcenode state db to expected value")
+	state_data, getErr := ResourceNodeState_get_all_from_db(wdb)
+	if getErr != nil {
+		log.Error.Fatalf("Error encountered while testing resourcenode state during wdb initialization: %v", getErr)
+	}
+	success_str := fmt.Sprintf("%v", reflect.DeepEqual(state_data, states))
+	log.Test.Printf("%s DOES DB RESOURCENODESTATE DEEPEQUAL EXPECTED RESOURCENODESTATE?", log.TestOutput(success_str, "true"))
+	if success_str != "true" {
+		log.Error.Fatalf("FAILED TEST WHILE INITIALIZING RESOURCENODESTATE DB, SAVED STATE NOT MATCH DATABASE")
+	}
+}
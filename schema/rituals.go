@@ -1,27 +1,214 @@
 // Package schema defines database and JSON schema as structs, as well as functions for creating and using these structs
 package schema
 
-// Defines Ritual struct
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/brct-james/guild-golems/log"
+)
+
+// Defines Ritual struct, what performing it does is described by Effect
 type Ritual struct {
 	Thing
 	ManaCost float64 `json:"mana-cost" binding:"required"`
+	KnownByDefault bool `json:"known-by-default" binding:"required"`
+	// Invoking invokers needed at the target locale to perform the ritual
+	RequiredInvokers int `json:"required-invokers" binding:"required"`
+	Effect RitualEffect `json:"effect" binding:"required"`
+}
+
+// Defines what a ritual does, only the fields for its Type are used
+// - summon_golem: summon a golem of Archetype with StartingStatus
+// - learn_ritual: learn the ritual RitualSymbol
+// - buff: add Amount to the user's Stat for Duration seconds
+// - upgrade_golem, recharge_golem, repair_golem, teleport: act on the golem given in the request
+type RitualEffect struct {
+	Type string `json:"type" binding:"required"`
+	Archetype string `json:"archetype,omitempty"`
+	StartingStatus string `json:"starting-status,omitempty"`
+	RitualSymbol string `json:"ritual-symbol,omitempty"`
+	Stat string `json:"stat,omitempty"`
+	Amount float64 `json:"amount,omitempty"`
+	Duration int `json:"duration,omitempty"`
+}
+
+// Stats a buff ritual can raise
+var BuffableStats = map[string]bool{"mana_regen": true}
+
+// Defines the ritual circle at a locale, invoking invokers there feed it mana which rituals cast there spend first
+type RitualCircle struct {
+	LocaleSymbol string `json:"locale-symbol" binding:"required"`
+	Mana float64 `json:"mana" binding:"required"`
 }
 
-// ritual info map
-var Rituals = map[string]Ritual {
-	"summon-invoker": NewRitual("Summon Invoker", "summon-invoker", "Spend mana to summon a new invoker, who can be used to help generate even more mana.", 600),
-	"summon-harvester": NewRitual("Summon Harvester", "summon-harvester", "Spend mana to summon a new harvester, who can be used to gather resources from nodes in the world.", 600),
+// Defines the body of ritual requests, every field is optional
+// LocaleSymbol defaults to the golem's locale for rituals acting on a golem and Starting_Locale_Symbol otherwise
+// DestinationSymbol is required by teleport, Repetitions defaults to 1
+type RitualBody struct {
+	GolemSymbol string `json:"golem_symbol"`
+	LocaleSymbol string `json:"locale_symbol"`
+	DestinationSymbol string `json:"destination_symbol"`
+	Repetitions int `json:"repetitions"`
 }
 
-func NewRitual(name string, symbol string, description string, manaCost float64) Ritual {
-	return Ritual{
-		Thing: Thing{
-			Name: name,
-			HasSymbol: HasSymbol{
-				Symbol: symbol,
-			},
-			Description: description,
-		},
-		ManaCost: manaCost,
+// Where and on what a ritual is performed, GolemIndex is -1 for rituals not acting on a golem
+type RitualTarget struct {
+	LocaleSymbol string
+	GolemIndex int
+	DestinationSymbol string
+}
+
+// Defines a temporary raise to one of the user's stats from a buff ritual
+type Buff struct {
+	RitualSymbol string `json:"ritual-symbol" binding:"required"`
+	Stat string `json:"stat" binding:"required"`
+	Amount float64 `json:"amount" binding:"required"`
+	ExpiresAt int64 `json:"expires-at" binding:"required"`
+}
+
+// Defines the result of performing a ritual, only what the ritual produced is set
+type RitualResult struct {
+	RitualSymbol string `json:"ritual-symbol" binding:"required"`
+	LocaleSymbol string `json:"locale-symbol" binding:"required"`
+	ManaSpent float64 `json:"mana-spent" binding:"required"`
+	Golem *Golem `json:"golem,omitempty"`
+	LearnedRitual string `json:"learned-ritual,omitempty"`
+	Buff *Buff `json:"buff,omitempty"`
+}
+
+// Defines the result of performing a ritual several times in one request, one entry in Results per repetition
+type RitualBatchResult struct {
+	RitualSymbol string `json:"ritual-symbol" binding:"required"`
+	LocaleSymbol string `json:"locale-symbol" binding:"required"`
+	Repetitions int `json:"repetitions" binding:"required"`
+	ManaSpent float64 `json:"mana-spent" binding:"required"`
+	Results []RitualResult `json:"results" binding:"required"`
+}
+
+// Effect types that may be performed more than once per request, repeating the rest would only fail or waste mana
+var Repeatable_Ritual_Effects = map[string]bool{"summon_golem": true, "upgrade_golem": true}
+
+// Returned when a ritual's effect exists but cannot be performed yet
+var ErrRitualUnimplemented = errors.New("ritual not yet implemented")
+
+// Returned when too few invoking invokers are at the ritual's locale
+var ErrNotEnoughInvokers = errors.New("not enough invokers")
+
+// ritual info map, loaded from json at startup
+var Rituals = map[string]Ritual {}
+
+// Unmarshals all rituals from json byte array
+func Ritual_unmarshal_all_json(ritual_json []byte) (map[string]Ritual, error) {
+	log.Debug.Println("Unmarshalling ritual.json")
+	nilRes := make(map[string]Ritual)
+	var rituals map[string]Ritual
+	err := json.Unmarshal(ritual_json, &rituals)
+	if err != nil {
+		return nilRes, err
 	}
-}
\ No newline at end of file
+	return rituals, nil
+}
+
+// Effect types the game needs a ritual for, golems could not be upgraded, recharged or repaired otherwise
+var Required_Ritual_Effects = []string{"upgrade_golem", "recharge_golem", "repair_golem"}
+
+// Check every ritual's effect is well formed and refers to things that exist
+// Also checks every effect in Required_Ritual_Effects and every golem archetype has a ritual,
+// and that new users know an invoker summon needing no invokers so they can start generating mana
+func ValidateRituals(rituals map[string]Ritual) error {
+	effects := make(map[string]bool)
+	summoned := make(map[string]bool)
+	canBootstrap := false
+	for _, ritual := range rituals {
+		effects[ritual.Effect.Type] = true
+		if ritual.Effect.Type == "summon_golem" {
+			summoned[ritual.Effect.Archetype] = true
+			if ritual.Effect.Archetype == "invoker" && ritual.RequiredInvokers == 0 && ritual.KnownByDefault {
+				canBootstrap = true
+			}
+		}
+	}
+	for _, effectType := range Required_Ritual_Effects {
+		if !effects[effectType] {
+			return fmt.Errorf("no ritual has the required effect %s", effectType)
+		}
+	}
+	for archetype := range GolemArchetypes {
+		if !summoned[archetype] {
+			return fmt.Errorf("no ritual summons the archetype %s", archetype)
+		}
+	}
+	if !canBootstrap {
+		return fmt.Errorf("no ritual known by default summons an invoker without needing invokers")
+	}
+	for symbol, ritual := range rituals {
+		if ritual.Symbol != symbol {
+			return fmt.Errorf("ritual %s has mismatched symbol %s", symbol, ritual.Symbol)
+		}
+		if ritual.ManaCost < 0 || ritual.RequiredInvokers < 0 {
+			return fmt.Errorf("ritual %s has negative mana cost or required invokers", symbol)
+		}
+		effect := ritual.Effect
+		switch effect.Type {
+		case "summon_golem":
+			if _, ok := GolemArchetypes[effect.Archetype]; !ok {
+				return fmt.Errorf("ritual %s summons unknown archetype %s", symbol, effect.Archetype)
+			}
+			if allowed, err := IsStatusAllowedForArchetype(effect.Archetype, effect.StartingStatus); err != nil || !allowed {
+				return fmt.Errorf("ritual %s summons %s with disallowed status %s", symbol, effect.Archetype, effect.StartingStatus)
+			}
+		case "learn_ritual":
+			if _, ok := rituals[effect.RitualSymbol]; !ok {
+				return fmt.Errorf("ritual %s teaches unknown ritual %s", symbol, effect.RitualSymbol)
+			}
+		case "buff":
+			if !BuffableStats[effect.Stat] || effect.Duration <= 0 {
+				return fmt.Errorf("ritual %s buffs unknown stat %s or has non-positive duration", symbol, effect.Stat)
+			}
+		case "upgrade_golem", "recharge_golem", "repair_golem", "teleport":
+		default:
+			return fmt.Errorf("ritual %s has unknown effect type %s", symbol, effect.Type)
+		}
+	}
+	return nil
+}
+
+// Get the symbols of rituals new users know, sorted
+func GetDefaultRitualSymbols() ([]string) {
+	symbols := make([]string, 0)
+	for symbol, ritual := range Rituals {
+		if ritual.KnownByDefault {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// Check whether the ritual acts on a golem given in the request
+func RitualTargetsGolem(ritual Ritual) bool {
+	switch ritual.Effect.Type {
+	case "upgrade_golem", "recharge_golem", "repair_golem", "teleport":
+		return true
+	}
+	return false
+}
+
+// Get the cheapest ritual that summons golems of archetype, ties go to the lowest symbol so the result never depends on map order
+func GetSummonRitual(archetype string) (Ritual, bool) {
+	var cheapest Ritual
+	found := false
+	for _, ritual := range Rituals {
+		if ritual.Effect.Type != "summon_golem" || ritual.Effect.Archetype != archetype {
+			continue
+		}
+		if !found || ritual.ManaCost < cheapest.ManaCost || (ritual.ManaCost == cheapest.ManaCost && ritual.Symbol < cheapest.Symbol) {
+			cheapest = ritual
+			found = true
+		}
+	}
+	return cheapest, found
+}
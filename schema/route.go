@@ -3,6 +3,7 @@ package schema
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -13,11 +14,40 @@ import (
 
 // Defines the characteristics of Routes between locales
 // TravelTime in seconds
+// Cost is charged on departure, in coins unless CostType is "mana"
 type Route struct {
 	Thing
 	DangerLevel int `json:"danger_level" binding:"required"`
 	TravelTime int `json:"travel_time" binding:"required"`
 	Cost int `json:"cost" binding:"required"`
+	CostType string `json:"cost_type"`
+}
+
+// Returned when no chain of routes connects two locales
+var ErrNoPathFound = errors.New("no path found")
+
+// Returned when a route does not exist or does not start where the golem is
+var ErrRouteUnavailable = errors.New("route unavailable")
+
+// Defines a planned chain of routes between two locales, with totals over every hop
+type RoutePlan struct {
+	OriginSymbol string `json:"origin_symbol" binding:"required"`
+	DestinationSymbol string `json:"destination_symbol" binding:"required"`
+	Optimize string `json:"optimize" binding:"required"`
+	RouteSymbols []string `json:"route_symbols" binding:"required"`
+	TotalTravelTime int `json:"total_travel_time" binding:"required"`
+	TotalCoinCost int `json:"total_coin_cost" binding:"required"`
+	TotalManaCost int `json:"total_mana_cost" binding:"required"`
+	TotalDanger int `json:"total_danger" binding:"required"`
+}
+
+// Get the origin and destination locale symbols from a route symbol of the form ORIGIN|DESTINATION|MODE
+func ParseRouteSymbol(routeSymbol string) (string, string, error) {
+	parts := strings.Split(routeSymbol, "|")
+	if len(parts) < 2 {
+		return "", "", fmt.Errorf("route %s is not of the form ORIGIN|DESTINATION|MODE", routeSymbol)
+	}
+	return parts[0], parts[1], nil
 }
 
 // Unmarshals route from json byte array
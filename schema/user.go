@@ -3,7 +3,9 @@ package schema
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/brct-james/guild-golems/log"
@@ -16,8 +18,23 @@ type User struct {
 	PublicUserInfo
 	ManaDetails
 	Golems []Golem `json:"golems" binding:"required"`
-	Inventory []LocationInventory `json:"inventory" binding:"required"`
+	Inventory map[string]LocationInventory `json:"inventory" binding:"required"`
 	KnownRituals []string `json:"known-rituals" binding:"required"`
+	Buildings map[string]map[string]Building `json:"buildings" binding:"required"`
+	// Next golem ID for each archetype, only ever counts up so symbols are never reused
+	GolemIdCounters map[string]int `json:"golem-id-counters" binding:"required"`
+	Buffs []Buff `json:"buffs" binding:"required"`
+	// Mana gathered by invokers at each locale, keyed by locale symbol
+	RitualCircles map[string]RitualCircle `json:"ritual-circles" binding:"required"`
+	ActiveResearch ResearchProgress `json:"active-research" binding:"required"`
+	// Level bought of each mana upgrade, keyed by upgrade symbol
+	ManaUpgrades map[string]int `json:"mana-upgrades" binding:"required"`
+}
+
+// Defines the response for the /my/account endpoint, the user along with how their mana cap and regen are made up
+type AccountInfoResponse struct {
+	User
+	ManaBreakdown ManaBreakdown `json:"mana-breakdown" binding:"required"`
 }
 
 // Defines the public User info for the /users/{username} endpoint
@@ -28,7 +45,40 @@ type PublicUserInfo struct {
 	UserSince int64 `json:"user-since" binding:"required"`
 }
 
+// Titles users can hold, from lowest to highest rank. New users start untitled
+var Titles = []string{"", "Apprentice", "Journeyman", "Adept", "Master", "Grandmaster"}
+
+// Get the rank of title in Titles, or -1 if it is not a known title
+func GetTitleRank(title string) int {
+	for rank, t := range Titles {
+		if strings.EqualFold(t, title) {
+			return rank
+		}
+	}
+	return -1
+}
+
+// Check user data for ritual in list of known rituals
+func DoesUserKnowRitual(userData User, ritualKey string) (bool) {
+	for _, ritual := range userData.KnownRituals {
+		if strings.EqualFold(ritual, ritualKey) {
+			// success state - user knows ritual
+			return true
+		}
+	}
+	// fail state - user doesnt know ritual
+	return false
+}
+
+// Returned when an action costs more mana than the user has
+var ErrInsufficientMana = errors.New("insufficient mana")
+
+// Mana cap and regen before upgrades, the stored ManaCap and ManaRegen are recalculated from these on every update
+var Base_Mana_Cap float64 = 21600.0
+var Base_Mana_Regen float64 = 1.0
+
 // Defines the schema for ManaDetails - a struct containing information on mana for players
+// ManaCap and ManaRegen include mana upgrades but not invokers or buffs
 type ManaDetails struct {
 	Mana float64 `json:"mana" binding:"required"`
 	ManaCap float64 `json:"mana-cap" binding:"required"`
@@ -36,12 +86,6 @@ type ManaDetails struct {
 	LastManaTick int64 `json:"last-mana-tick" binding:"required"`
 }
 
-// Defines the schema for LocationInventories - lists of items owned by the player at a certain location
-type LocationInventory struct {
-	LocationSymbol string `json:"location-symbol" binding:"required"`
-	Contents []Resource `json:"contents" binding:"required"`
-}
-
 func NewUser(token string, username string) User {
 	return User{
 		Token: token,
@@ -53,16 +97,19 @@ func NewUser(token string, username string) User {
 		},
 		ManaDetails: ManaDetails{
 			Mana: 3600.0,
-			ManaCap: 21600.0,
-			ManaRegen: 1.0,
+			ManaCap: Base_Mana_Cap,
+			ManaRegen: Base_Mana_Regen,
 			LastManaTick: time.Now().Unix(),
 		},
 		Golems: make([]Golem, 0),
-		Inventory: make([]LocationInventory, 0),
-		KnownRituals: []string{
-			"summon-invoker",
-			"summon-harvester",
-		},
+		Inventory: make(map[string]LocationInventory),
+		KnownRituals: GetDefaultRitualSymbols(),
+		Buildings: make(map[string]map[string]Building),
+		GolemIdCounters: make(map[string]int),
+		Buffs: make([]Buff, 0),
+		RitualCircles: make(map[string]RitualCircle),
+		ActiveResearch: ResearchProgress{},
+		ManaUpgrades: make(map[string]int),
 	}
 }
 
@@ -102,4 +149,17 @@ func GetUserFromDB (token string, udb rdb.Database) (User, bool, error) {
 		return User{}, false, unmarshalErr
 	}
 	return uData, true, nil
-}
\ No newline at end of file
+}
+// Deep copy userData so changes to the copy, e.g. in a retried transaction, leave the original untouched
+func CopyUser(userData User) (User, error) {
+	uJson, marshalErr := json.Marshal(userData)
+	if marshalErr != nil {
+		return User{}, marshalErr
+	}
+	copied := User{}
+	unmarshalErr := json.Unmarshal(uJson, &copied)
+	if unmarshalErr != nil {
+		return User{}, unmarshalErr
+	}
+	return copied, nil
+}
@@ -3,6 +3,7 @@ package schema
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 
@@ -15,14 +16,8 @@ type World struct {
 	RegionSymbols []string `json:"region_symbols" binding:"required"`
 }
 
-type WorldSummaryResponse struct {
-	World World `json:"world" binding:"required"`
-	Regions map[string]Region `json:"regions" binding:"required"`
-	Locales map[string]Locale `json:"locales" binding:"required"`
-	Routes map[string]Route `json:"routes" binding:"required"`
-	Resources map[string]Resource `json:"resources" binding:"required"`
-	ResourceNodes map[string]ResourceNode `json:"resource_nodes" binding:"required"`
-}
+// Returned when world data needed for an action could not be gotten from the wdb
+var ErrWorldDataUnavailable = errors.New("world data unavailable")
 
 // Unmarshals world from json byte array
 func World_unmarshal_json(world_json []byte) (World, error) {